@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"pumu/internal/cache"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the folder size cache",
+	Long: `pumu remembers the size of each folder it measures, so that repeat scans
+skip walking folders that have not changed. The cache lives in
+$XDG_CACHE_HOME/pumu (or ~/.cache/pumu).`,
+	Example: `  pumu cache clear    # forget every cached size`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:           "clear",
+	Short:         "Delete the folder size cache",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		path, err := cache.DefaultPath()
+		if err != nil {
+			return err
+		}
+		if err := cache.Clear(path); err != nil {
+			return err
+		}
+		color.Green("🧹 Cleared size cache at %s", path)
+		return nil
+	},
+}
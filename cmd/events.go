@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"pumu/pkg/pumu"
+
+	"github.com/spf13/cobra"
+)
+
+// eventRecord is one line of the --events ndjson stream. Only the objects
+// that apply to the event are present.
+type eventRecord struct {
+	SchemaVersion int             `json:"schema_version"`
+	Event         pumu.EventKind  `json:"event"`
+	Time          string          `json:"time"`
+	Folder        *folderRecord   `json:"folder,omitempty"`
+	Analysis      *analysisRecord `json:"analysis,omitempty"`
+	Project       *projectRef     `json:"project,omitempty"`
+	Health        *healthRecord   `json:"health,omitempty"`
+	Error         string          `json:"error,omitempty"`
+}
+
+type analysisRecord struct {
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+type projectRef struct {
+	Dir            string `json:"dir"`
+	PackageManager string `json:"package_manager"`
+	Ecosystem      string `json:"ecosystem"`
+}
+
+type healthRecord struct {
+	Healthy bool     `json:"healthy"`
+	Issues  []string `json:"issues"`
+}
+
+func newEventRecord(e pumu.Event) eventRecord {
+	record := eventRecord{
+		SchemaVersion: schemaVersion,
+		Event:         e.Kind,
+		Time:          time.Now().UTC().Format(time.RFC3339Nano),
+	}
+
+	switch e.Kind {
+	case pumu.HealthChecked, pumu.ReinstallStarted, pumu.ReinstallFinished, pumu.ReinstallFailed:
+		record.Project = &projectRef{
+			Dir:            e.Project.Dir,
+			PackageManager: string(e.Project.PM),
+			Ecosystem:      e.Project.Ecosystem(),
+		}
+	default:
+		folder := newFolderRecord(e.Folder)
+		record.Folder = &folder
+	}
+	if e.Kind == pumu.FolderAnalyzed {
+		record.Analysis = &analysisRecord{Score: e.Result.Score, Reason: plainReason(e.Result.Reason)}
+	}
+	if e.Kind == pumu.HealthChecked {
+		record.Health = &healthRecord{Healthy: e.Health.Healthy, Issues: nonNil(e.Health.Issues)}
+	}
+	if e.Err != nil {
+		record.Error = e.Err.Error()
+	}
+	return record
+}
+
+// eventStream returns the callback that writes events to w in the --events
+// format, or nil if --events is not set.
+func eventStream(cmd *cobra.Command, w io.Writer) (func(pumu.Event), error) {
+	format, err := cmd.Flags().GetString("events")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "":
+		return nil, nil
+	case "ndjson":
+	default:
+		return nil, fmt.Errorf("invalid --events %q: want ndjson", format)
+	}
+
+	enc := json.NewEncoder(w)
+	return func(e pumu.Event) {
+		// Events are best effort; a closed stderr must not stop the work.
+		_ = enc.Encode(newEventRecord(e))
+	}, nil
+}
+
+// withEvents returns opts with fn called on every event after any callback
+// opts already has.
+func withEvents(opts pumu.Options, fn func(pumu.Event)) pumu.Options {
+	prev := opts.OnEvent
+	if prev == nil {
+		opts.OnEvent = fn
+		return opts
+	}
+	opts.OnEvent = func(e pumu.Event) {
+		prev(e)
+		fn(e)
+	}
+	return opts
+}
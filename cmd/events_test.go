@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"pumu/pkg/pumu"
+
+	"github.com/spf13/cobra"
+)
+
+func TestEventStreamRecords(t *testing.T) {
+	folder := pumu.TargetFolder{Path: "/src/web/node_modules", Ecosystem: "node", Size: 4096, Apparent: 1000}
+	project := pumu.Project{Dir: "/src/api", PM: pumu.Pip}
+	folderJSON := `{"path": "/src/web/node_modules", "ecosystem": "node", "size_bytes": 4096, "apparent_bytes": 1000}`
+	projectJSON := `{"dir": "/src/api", "package_manager": "pip", "ecosystem": "python"}`
+
+	tests := []struct {
+		event pumu.Event
+		want  string
+	}{
+		{
+			pumu.Event{Kind: pumu.TargetFound, Folder: folder},
+			`{"event": "target_found", "folder": ` + folderJSON + `}`,
+		},
+		{
+			pumu.Event{Kind: pumu.FolderSized, Folder: folder},
+			`{"event": "folder_sized", "folder": ` + folderJSON + `}`,
+		},
+		{
+			pumu.Event{Kind: pumu.FolderAnalyzed, Folder: folder, Result: pumu.PruneResult{TargetFolder: folder, Score: 90, Reason: "📦 Build cache (re-generable)"}},
+			`{"event": "folder_analyzed", "folder": ` + folderJSON + `, "analysis": {"score": 90, "reason": "Build cache (re-generable)"}}`,
+		},
+		{
+			pumu.Event{Kind: pumu.DeleteStarted, Folder: folder},
+			`{"event": "delete_started", "folder": ` + folderJSON + `}`,
+		},
+		{
+			pumu.Event{Kind: pumu.DeleteFinished, Folder: folder},
+			`{"event": "delete_finished", "folder": ` + folderJSON + `}`,
+		},
+		{
+			pumu.Event{Kind: pumu.DeleteFailed, Folder: folder, Err: errors.New("permission denied")},
+			`{"event": "delete_failed", "folder": ` + folderJSON + `, "error": "permission denied"}`,
+		},
+		{
+			pumu.Event{Kind: pumu.HealthChecked, Project: project, Health: pumu.HealthResult{Dir: "/src/api", PM: pumu.Pip, Healthy: true}},
+			`{"event": "health_checked", "project": ` + projectJSON + `, "health": {"healthy": true, "issues": []}}`,
+		},
+		{
+			pumu.Event{Kind: pumu.ReinstallStarted, Project: project},
+			`{"event": "reinstall_started", "project": ` + projectJSON + `}`,
+		},
+		{
+			pumu.Event{Kind: pumu.ReinstallFinished, Project: project},
+			`{"event": "reinstall_finished", "project": ` + projectJSON + `}`,
+		},
+		{
+			pumu.Event{Kind: pumu.ReinstallFailed, Project: project, Err: errors.New("exit status 1")},
+			`{"event": "reinstall_failed", "project": ` + projectJSON + `, "error": "exit status 1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.event.Kind), func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().String("events", "ndjson", "")
+			var out bytes.Buffer
+			emit, err := eventStream(cmd, &out)
+			if err != nil {
+				t.Fatalf("eventStream() error = %v", err)
+			}
+			emit(tt.event)
+
+			line, rest, _ := bytes.Cut(out.Bytes(), []byte("\n"))
+			if len(rest) != 0 {
+				t.Fatalf("eventStream() wrote more than one line: %q", out.String())
+			}
+			var got map[string]any
+			if err := json.Unmarshal(line, &got); err != nil {
+				t.Fatalf("eventStream() wrote invalid JSON %q: %v", line, err)
+			}
+			if _, err := time.Parse(time.RFC3339Nano, got["time"].(string)); err != nil {
+				t.Errorf("time = %v, want an RFC 3339 timestamp", got["time"])
+			}
+			delete(got, "time")
+
+			var want map[string]any
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			want["schema_version"] = float64(schemaVersion)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("eventStream() wrote %s, want %s", line, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventStreamFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantNil bool
+		wantErr bool
+	}{
+		{"", true, false},
+		{"ndjson", false, false},
+		{"json", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().String("events", tt.format, "")
+			emit, err := eventStream(cmd, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("eventStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (emit == nil) != tt.wantNil {
+				t.Errorf("eventStream() returned nil = %v, want %v", emit == nil, tt.wantNil)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"pumu/pkg/pumu"
+
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags registers the flags that narrow which folders a command handles.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("min-size", "", "Only folders reclaiming at least this much, e.g. 200MB or 1GB")
+	cmd.Flags().String("older-than", "", "Only folders whose project is untouched for this long, e.g. 60d, 2w or 36h")
+	cmd.Flags().StringSlice("type", nil, "Only these folder names, e.g. node_modules,target")
+	cmd.Flags().StringSlice("ecosystem", nil, "Only these ecosystems or package managers, e.g. node,cargo")
+}
+
+// filterOptions returns the filter set by the flags of addFilterFlags.
+// Folder types must be among targets, and ecosystems known to pumu.
+func filterOptions(cmd *cobra.Command, targets []string) (pumu.Filter, error) {
+	var filter pumu.Filter
+
+	minSize, err := cmd.Flags().GetString("min-size")
+	if err != nil {
+		return filter, err
+	}
+	if minSize != "" {
+		if filter.MinSize, err = pumu.ParseSize(minSize); err != nil {
+			return filter, fmt.Errorf("--min-size: %w", err)
+		}
+	}
+
+	olderThan, err := cmd.Flags().GetString("older-than")
+	if err != nil {
+		return filter, err
+	}
+	if olderThan != "" {
+		if filter.OlderThan, err = pumu.ParseAge(olderThan); err != nil {
+			return filter, fmt.Errorf("--older-than: %w", err)
+		}
+	}
+
+	if filter.Types, err = cmd.Flags().GetStringSlice("type"); err != nil {
+		return filter, err
+	}
+	for _, t := range filter.Types {
+		if !slices.Contains(targets, t) {
+			return filter, fmt.Errorf("--type: %q is not a target folder name", t)
+		}
+	}
+
+	if filter.Ecosystems, err = cmd.Flags().GetStringSlice("ecosystem"); err != nil {
+		return filter, err
+	}
+	for _, e := range filter.Ecosystems {
+		if !pumu.IsEcosystem(e) {
+			return filter, fmt.Errorf("--ecosystem: %q is not a known ecosystem or package manager", e)
+		}
+	}
+	return filter, nil
+}
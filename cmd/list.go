@@ -1,28 +1,106 @@
 package cmd
 
 import (
-	"pumu/internal/scanner"
+	"context"
+	"io"
+
+	"pumu/pkg/pumu"
 
 	"github.com/spf13/cobra"
 )
 
 func init() {
+	listCmd.Flags().Bool("include-unverified", false, "Include folders whose parent has no matching project manifest")
+	listCmd.Flags().Bool("explain-skips", false, "Report every directory an ignore rule kept the scan out of, and the rule")
+	addFilterFlags(listCmd)
+	addGroupFlag(listCmd)
 	rootCmd.AddCommand(listCmd)
 }
 
 var listCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [path...]",
 	Short: "List heavy dependency folders (dry-run)",
 	Long:  `Scans for heavy dependency folders and lists them without deleting anything.`,
 	Example: `  pumu list            # scan current directory
-  pumu list -p ~/dev   # scan a custom path`,
+  pumu list -p ~/dev   # scan a custom path
+  pumu list ~/work ~/oss  # scan several paths in one run
+  pumu list --type node_modules --min-size 200MB
+  pumu list --group-by project   # subtotals per project
+  pumu list --explain-skips      # show which ignore rule skipped which directory
+  pumu list -o json | jq '.folders[] | select(.size_bytes > 1e9) | .path'`,
+	Args:          cobra.ArbitraryArgs,
 	SilenceErrors: true,
 	SilenceUsage:  true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path, err := cmd.Root().PersistentFlags().GetString("path")
+		roots, err := scanRoots(cmd, args)
+		if err != nil {
+			return err
+		}
+		opts, err := scanOptions(cmd)
 		if err != nil {
 			return err
 		}
-		return scanner.SweepDir(path, true, false, true)
+		if opts.IncludeUnverified, err = cmd.Flags().GetBool("include-unverified"); err != nil {
+			return err
+		}
+		if opts.ExplainSkips, err = cmd.Flags().GetBool("explain-skips"); err != nil {
+			return err
+		}
+		if opts.Filter, err = filterOptions(cmd, opts.Targets); err != nil {
+			return err
+		}
+		groupBy, err := groupByOf(cmd)
+		if err != nil {
+			return err
+		}
+		format, err := outputFormatOf(cmd)
+		if err != nil {
+			return err
+		}
+		if format != outputTable {
+			return writeList(cmd.Context(), cmd.OutOrStdout(), format, roots, opts, groupBy)
+		}
+		run := sweepRun{dryRun: true, noSelect: true, groupBy: groupBy}
+		return run.execute(cmd.Context(), roots, opts)
 	},
 }
+
+// writeList scans roots and writes the folders found to w in format, with
+// their groups if groupBy is set. If a root cannot be walked, what was found
+// elsewhere is still written before the error is returned.
+func writeList(ctx context.Context, w io.Writer, format outputFormat, roots []string, opts pumu.Options, groupBy pumu.GroupBy) error {
+	folders, report, scanErr := pumu.New(opts).Scan(ctx, roots)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	out := listOutput{scanOutput: newScanOutput("list", report), Folders: []folderRecord{}}
+	columns := append([]string(nil), folderColumns...)
+	groupOf := make(map[string]string)
+	if groupBy != "" {
+		columns = append(columns, "group")
+		out.Groups = []groupRecord{}
+		for _, g := range pumu.GroupFolders(folders, groupBy, report.Roots) {
+			out.Groups = append(out.Groups, newGroupRecord(g))
+			for _, f := range g.Folders {
+				groupOf[f.Path] = g.Key
+			}
+		}
+	}
+
+	rows := make([][]string, 0, len(folders))
+	for _, f := range folders {
+		record := newFolderRecord(f)
+		out.Folders = append(out.Folders, record)
+		row := record.row()
+		if groupBy != "" {
+			row = append(row, groupOf[f.Path])
+		}
+		rows = append(rows, append(row, record.members()))
+	}
+	columns = append(columns, membersColumn)
+	if err := writeOutput(w, format, out, columns, rows); err != nil {
+		return err
+	}
+	return scanErr
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"pumu/pkg/pumu"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaVersion is the version of the JSON and CSV output schema. It is bumped
+// whenever a field or column is renamed, removed or changes meaning; new
+// fields and trailing columns may be added without bumping it.
+const schemaVersion = 1
+
+// outputFormat is the format results are written in, chosen with --output.
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table" // colored tables for people
+	outputJSON  outputFormat = "json"  // one JSON document
+	outputCSV   outputFormat = "csv"   // a header row and one row per result
+)
+
+// outputFormatOf returns the --output format, rejecting unknown ones.
+func outputFormatOf(cmd *cobra.Command) (outputFormat, error) {
+	value, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
+	switch format := outputFormat(value); format {
+	case outputTable, outputJSON, outputCSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid --output %q: want table, json or csv", value)
+	}
+}
+
+// tableOnly fails unless the --output format is table, for commands that
+// have no machine-readable output.
+func tableOnly(cmd *cobra.Command) error {
+	format, err := outputFormatOf(cmd)
+	if err != nil {
+		return err
+	}
+	if format != outputTable {
+		return fmt.Errorf("--output %s is not supported by %s; use list, prune or repair", format, cmd.Name())
+	}
+	return nil
+}
+
+// scanOutput is the part of every JSON document describing the scan itself.
+type scanOutput struct {
+	SchemaVersion int           `json:"schema_version"`
+	Command       string        `json:"command"`
+	Roots         []string      `json:"roots"`
+	Unverified    []string      `json:"unverified"`
+	SkippedMounts []mountRecord `json:"skipped_mounts"`
+	// SkippedDirs are only reported with --explain-skips.
+	SkippedDirs []skipRecord `json:"skipped_dirs,omitempty"`
+	Filtered    int          `json:"filtered"`
+}
+
+type skipRecord struct {
+	Path string `json:"path"`
+	Rule string `json:"rule"`
+}
+
+type mountRecord struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+func newScanOutput(command string, report pumu.Report) scanOutput {
+	out := scanOutput{
+		SchemaVersion: schemaVersion,
+		Command:       command,
+		Roots:         nonNil(report.Roots),
+		Unverified:    nonNil(report.Unverified),
+		SkippedMounts: []mountRecord{},
+		Filtered:      report.Filtered,
+	}
+	for _, m := range report.SkippedMounts {
+		out.SkippedMounts = append(out.SkippedMounts, mountRecord{Path: m.Path, Reason: m.Reason})
+	}
+	for _, d := range report.SkippedDirs {
+		out.SkippedDirs = append(out.SkippedDirs, skipRecord{Path: d.Path, Rule: d.Rule})
+	}
+	return out
+}
+
+// folderRecord is one folder in list and prune output.
+type folderRecord struct {
+	Path          string `json:"path"`
+	Ecosystem     string `json:"ecosystem"`
+	SizeBytes     int64  `json:"size_bytes"`
+	ApparentBytes int64  `json:"apparent_bytes"`
+	// Members are the folders of an aggregate such as <project>/**/__pycache__.
+	Members []string `json:"members,omitempty"`
+}
+
+func newFolderRecord(f pumu.TargetFolder) folderRecord {
+	return folderRecord{
+		Path:          f.Path,
+		Ecosystem:     f.Ecosystem,
+		SizeBytes:     f.Size,
+		ApparentBytes: f.Apparent,
+		Members:       f.Members,
+	}
+}
+
+var folderColumns = []string{"path", "ecosystem", "size_bytes", "apparent_bytes"}
+
+func (r folderRecord) row() []string {
+	return []string{r.Path, r.Ecosystem, strconv.FormatInt(r.SizeBytes, 10), strconv.FormatInt(r.ApparentBytes, 10)}
+}
+
+// membersColumn is the trailing CSV column listing the folders of an
+// aggregate, whose path, such as <project>/**/__pycache__, is not a real one.
+const membersColumn = "members"
+
+// members returns the folders of an aggregate joined with "; ", or "".
+func (r folderRecord) members() string { return strings.Join(r.Members, "; ") }
+
+type listOutput struct {
+	scanOutput
+	Folders []folderRecord `json:"folders"`
+	Groups  []groupRecord  `json:"groups,omitempty"`
+}
+
+// groupRecord is one group in list output with --group-by.
+type groupRecord struct {
+	Key            string   `json:"key"`
+	PackageManager string   `json:"package_manager,omitempty"`
+	SizeBytes      int64    `json:"size_bytes"`
+	ApparentBytes  int64    `json:"apparent_bytes"`
+	Folders        []string `json:"folders"`
+}
+
+func newGroupRecord(g pumu.Group) groupRecord {
+	record := groupRecord{
+		Key:            g.Key,
+		PackageManager: string(g.PM),
+		SizeBytes:      g.Size,
+		ApparentBytes:  g.Apparent,
+	}
+	for _, f := range g.Folders {
+		record.Folders = append(record.Folders, f.Path)
+	}
+	return record
+}
+
+// pruneRecord is one analyzed folder in prune output.
+type pruneRecord struct {
+	folderRecord
+	Score    int    `json:"score"`
+	Reason   string `json:"reason"`
+	Prunable bool   `json:"prunable"`
+	Removed  bool   `json:"removed"`
+}
+
+var pruneColumns = append(append([]string(nil), folderColumns...), "score", "reason", "prunable", "removed", membersColumn)
+
+func (r pruneRecord) row() []string {
+	return append(r.folderRecord.row(),
+		strconv.Itoa(r.Score), r.Reason, strconv.FormatBool(r.Prunable), strconv.FormatBool(r.Removed), r.members())
+}
+
+type pruneOutput struct {
+	scanOutput
+	Threshold int           `json:"threshold"`
+	DryRun    bool          `json:"dry_run"`
+	Folders   []pruneRecord `json:"folders"`
+}
+
+// projectRecord is one checked project in repair output.
+type projectRecord struct {
+	Dir            string   `json:"dir"`
+	PackageManager string   `json:"package_manager"`
+	Ecosystem      string   `json:"ecosystem"`
+	Healthy        bool     `json:"healthy"`
+	Issues         []string `json:"issues"`
+	Repaired       bool     `json:"repaired"`
+	Error          string   `json:"error,omitempty"`
+}
+
+var projectColumns = []string{"dir", "package_manager", "ecosystem", "healthy", "issues", "repaired", "error"}
+
+func (r projectRecord) row() []string {
+	return []string{
+		r.Dir, r.PackageManager, r.Ecosystem, strconv.FormatBool(r.Healthy),
+		strings.Join(r.Issues, "; "), strconv.FormatBool(r.Repaired), r.Error,
+	}
+}
+
+type repairOutput struct {
+	scanOutput
+	Projects []projectRecord `json:"projects"`
+}
+
+// writeOutput writes doc as indented JSON, or columns and rows as CSV.
+func writeOutput(w io.Writer, format outputFormat, doc any, columns []string, rows [][]string) error {
+	if format == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// plainReason strips the leading status emoji from a prune reason.
+func plainReason(reason string) string {
+	if r, _ := utf8.DecodeRuneInString(reason); r < utf8.RuneSelf {
+		return reason
+	}
+	if _, rest, ok := strings.Cut(reason, " "); ok {
+		return rest
+	}
+	return reason
+}
+
+// nonNil returns s, or an empty slice if s is nil, so JSON shows [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
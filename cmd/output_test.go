@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"pumu/pkg/pumu"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// outputTree creates a tree with a node project, a Python project with two
+// __pycache__ folders and an unverified dist, and returns its root. Files
+// are empty, so that sizes do not depend on the filesystem.
+func outputTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"web/node_modules", "api/__pycache__", "api/app/__pycache__", "misc/dist"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"web/package-lock.json", "api/requirements.txt"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(file)), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// sizeField matches a JSON size_bytes value.
+var sizeField = regexp.MustCompile(`"size_bytes": \d+`)
+
+// checkGolden compares out to testdata/name, with root written as ROOT and,
+// since disk usage depends on the filesystem, size_bytes as SIZE.
+func checkGolden(t *testing.T, name, root string, out []byte) {
+	t.Helper()
+	got := []byte(strings.ReplaceAll(string(out), root, "ROOT"))
+	if strings.HasSuffix(name, ".csv") {
+		got = maskCSVSizes(t, got)
+	} else {
+		got = sizeField.ReplaceAll(got, []byte(`"size_bytes": "SIZE"`))
+	}
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path) //nolint:gosec // golden file of this test
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s output differs from %s:\n%s", name, path, got)
+	}
+}
+
+// maskCSVSizes replaces the values of the size_bytes column of out with SIZE.
+func maskCSVSizes(t *testing.T, out []byte) []byte {
+	t.Helper()
+	rows, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) == 0 {
+		return out
+	}
+	if col := slices.Index(rows[0], "size_bytes"); col >= 0 {
+		for _, row := range rows[1:] {
+			row[col] = "SIZE"
+		}
+	}
+	var masked bytes.Buffer
+	w := csv.NewWriter(&masked)
+	if err := w.WriteAll(rows); err != nil {
+		t.Fatal(err)
+	}
+	return masked.Bytes()
+}
+
+func TestWriteListGolden(t *testing.T) {
+	root := outputTree(t)
+	tests := []struct {
+		golden  string
+		format  outputFormat
+		groupBy pumu.GroupBy
+	}{
+		{"list.json", outputJSON, ""},
+		{"list.csv", outputCSV, ""},
+		{"list_grouped.json", outputJSON, pumu.GroupByProject},
+		{"list_grouped.csv", outputCSV, pumu.GroupByProject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeList(context.Background(), &out, tt.format, []string{root}, pumu.Options{NoCache: true}, tt.groupBy); err != nil {
+				t.Fatalf("writeList() error = %v", err)
+			}
+			checkGolden(t, tt.golden, root, out.Bytes())
+		})
+	}
+}
+
+func TestPruneWriteGolden(t *testing.T) {
+	root := outputTree(t)
+	for _, format := range []outputFormat{outputJSON, outputCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var out bytes.Buffer
+			run := pruneRun{threshold: 60, dryRun: true}
+			if err := run.write(context.Background(), &out, format, []string{root}, pumu.Options{NoCache: true}); err != nil {
+				t.Fatalf("write() error = %v", err)
+			}
+			checkGolden(t, "prune."+string(format), root, out.Bytes())
+		})
+	}
+}
+
+func TestWriteRepairGolden(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell script as the interpreter")
+	}
+	for _, format := range []outputFormat{outputJSON, outputCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			// A pip project whose venv runs, and a conda project that names no
+			// environment and, with no conda on PATH, cannot be reinstalled.
+			root := t.TempDir()
+			bin := filepath.Join(root, "api", ".venv", "bin")
+			if err := os.MkdirAll(bin, 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(bin, "python"), []byte("#!/bin/sh\nexit 0\n"), 0o700); err != nil { //nolint:gosec // test script must be executable
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, "api", "requirements.txt"), nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Join(root, "ml"), 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, "ml", "environment.yml"), []byte("channels: []\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", t.TempDir())
+
+			var out bytes.Buffer
+			if err := writeRepair(context.Background(), &out, format, []string{root}, pumu.Options{}); err != nil {
+				t.Fatalf("writeRepair() error = %v", err)
+			}
+			checkGolden(t, "repair."+string(format), root, out.Bytes())
+		})
+	}
+}
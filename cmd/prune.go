@@ -1,8 +1,14 @@
 package cmd
 
 import (
-	"pumu/internal/scanner"
+	"context"
+	"fmt"
+	"io"
+	"strings"
 
+	"pumu/pkg/pumu"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -12,33 +18,201 @@ func init() {
 	// --dry-run is also exposed on root as a persistent flag so other cmds can share it,
 	// but prune registers its own local copy to avoid double-registration.
 	pruneCmd.Flags().Bool("dry-run", false, "Only analyze and list, don't delete")
+	pruneCmd.Flags().Bool("include-unverified", false, "Include folders whose parent has no matching project manifest")
+	addFilterFlags(pruneCmd)
 	rootCmd.AddCommand(pruneCmd)
 }
 
 var pruneCmd = &cobra.Command{
-	Use:   "prune",
+	Use:   "prune [path...]",
 	Short: "Prune dependency folders by staleness score",
 	Long: `Analyzes dependency folders and removes those whose staleness score
 is above the given threshold. Use --dry-run to preview without deleting.`,
 	Example: `  pumu prune                          # prune with default threshold (50)
   pumu prune --threshold 70           # only prune if score >= 70
   pumu prune --dry-run                # preview without deleting
-  pumu prune --dry-run -p ~/projects  # preview on a custom path`,
+  pumu prune --dry-run -p ~/projects  # preview on a custom path
+  pumu prune --dry-run ~/work ~/oss   # preview on several paths
+  pumu prune --dry-run -o csv > prune.csv`,
+	Args:          cobra.ArbitraryArgs,
 	SilenceErrors: true,
 	SilenceUsage:  true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path, err := cmd.Root().PersistentFlags().GetString("path")
+		roots, err := scanRoots(cmd, args)
 		if err != nil {
 			return err
 		}
-		threshold, err := cmd.Flags().GetInt("threshold")
+		opts, err := scanOptions(cmd)
 		if err != nil {
 			return err
 		}
-		dryRun, err := cmd.Flags().GetBool("dry-run")
+		var run pruneRun
+		if run.threshold, err = cmd.Flags().GetInt("threshold"); err != nil {
+			return err
+		}
+		if run.dryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+			return err
+		}
+		if opts.IncludeUnverified, err = cmd.Flags().GetBool("include-unverified"); err != nil {
+			return err
+		}
+		if opts.Filter, err = filterOptions(cmd, opts.Targets); err != nil {
+			return err
+		}
+		format, err := outputFormatOf(cmd)
 		if err != nil {
 			return err
 		}
-		return scanner.PruneDir(path, threshold, dryRun)
+		if format != outputTable {
+			return run.write(cmd.Context(), cmd.OutOrStdout(), format, roots, opts)
+		}
+		return run.execute(cmd.Context(), roots, opts)
 	},
 }
+
+// pruneRun holds the settings of a prune run.
+type pruneRun struct {
+	threshold int  // minimum staleness score to delete
+	dryRun    bool // only analyze, never delete
+}
+
+// execute analyzes the dependency folders under roots and deletes those
+// scoring at least threshold. Canceling ctx stops the scan, lets deletions
+// already under way finish and reports what was actually removed; ctx.Err()
+// is then returned.
+func (r pruneRun) execute(ctx context.Context, roots []string, opts pumu.Options) error {
+	roots = pumu.NormalizeRoots(roots)
+
+	if r.dryRun {
+		color.Cyan("🌿 Analyzing safely deletable folders in %s (dry-run)...\n", quoteRoots(roots))
+	} else {
+		color.Cyan("🌿 Pruning safely deletable folders in %s...\n", quoteRoots(roots))
+	}
+
+	color.Yellow("🧐 Measuring and analyzing folders as they are found...")
+
+	var shown int
+	opts = withEvents(opts, func(e pumu.Event) {
+		if e.Kind != pumu.FolderAnalyzed {
+			return
+		}
+		if shown == 0 {
+			printPruneHeader()
+		}
+		printPruneRow(e.Result, r.threshold)
+		shown++
+	})
+	s := pumu.New(opts)
+	results, report, err := s.Analyze(ctx, roots)
+
+	if ctx.Err() != nil {
+		color.Yellow("\n⚠️  Interrupted! The scan did not finish; nothing was deleted.")
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scan: %w", err)
+	}
+	printReport(report)
+
+	if len(results) == 0 {
+		color.Green("✨ No heavy folders found!\n")
+		return nil
+	}
+
+	var prunable []pumu.TargetFolder
+	var prunableSize, totalSize int64
+	for _, res := range results {
+		totalSize += res.Size
+		if res.Score >= r.threshold {
+			prunable = append(prunable, res.TargetFolder)
+			prunableSize += res.Size
+		}
+	}
+
+	// Summary
+	fmt.Println(strings.Repeat("-", 124))
+
+	if len(prunable) == 0 {
+		color.Green("✨ No folders meet the prune threshold (score ≥ %d).", r.threshold)
+		color.Cyan("🤓 Total found: %s across %d folders\n", formatSize(totalSize), len(results))
+		return nil
+	}
+
+	if r.dryRun {
+		color.Green("🌿 Analysis complete! %d/%d folders can be pruned (score ≥ %d).",
+			len(prunable), len(results), r.threshold)
+		color.Cyan("🤓 Space that can be freed: %s (of %s total found)\n",
+			formatSize(prunableSize), formatSize(totalSize))
+		return nil
+	}
+
+	// Actually delete prunable folders
+	color.Yellow("\n🗑️  Deleting %d folders concurrently...", len(prunable))
+
+	res, err := s.Delete(ctx, prunable)
+	if err != nil {
+		printInterrupted(len(res.Removed), len(prunable), res.Freed)
+		return err
+	}
+
+	color.Green("\n🌿 Prune complete! Removed %d folders (score ≥ %d).", len(res.Removed), r.threshold)
+	color.Cyan("💾 Space freed: %s (of %s total found)\n",
+		formatSize(res.Freed), formatSize(totalSize))
+
+	return nil
+}
+
+// write analyzes the dependency folders under roots, deletes those scoring at
+// least threshold unless dryRun is set, and writes every analyzed folder to w
+// in format. If ctx is canceled during deletion, the folders actually removed
+// are still written before ctx.Err() is returned.
+func (r pruneRun) write(ctx context.Context, w io.Writer, format outputFormat, roots []string, opts pumu.Options) error {
+	s := pumu.New(opts)
+	results, report, err := s.Analyze(ctx, roots)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scan: %w", err)
+	}
+
+	var prunable []pumu.TargetFolder
+	for _, res := range results {
+		if res.Score >= r.threshold {
+			prunable = append(prunable, res.TargetFolder)
+		}
+	}
+
+	removed := make(map[string]bool)
+	var deleteErr error
+	if !r.dryRun && len(prunable) > 0 {
+		var res pumu.DeleteResult
+		res, deleteErr = s.Delete(ctx, prunable)
+		for _, f := range res.Removed {
+			removed[f.Path] = true
+		}
+	}
+
+	out := pruneOutput{
+		scanOutput: newScanOutput("prune", report),
+		Threshold:  r.threshold,
+		DryRun:     r.dryRun,
+		Folders:    []pruneRecord{},
+	}
+	rows := make([][]string, 0, len(results))
+	for _, res := range results {
+		record := pruneRecord{
+			folderRecord: newFolderRecord(res.TargetFolder),
+			Score:        res.Score,
+			Reason:       plainReason(res.Reason),
+			Prunable:     res.Score >= r.threshold,
+			Removed:      removed[res.Path],
+		}
+		out.Folders = append(out.Folders, record)
+		rows = append(rows, record.row())
+	}
+	if err := writeOutput(w, format, out, pruneColumns, rows); err != nil {
+		return err
+	}
+	return deleteErr
+}
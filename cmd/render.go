@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"pumu/pkg/pumu"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// addGroupFlag registers --group-by, which shows folders as a tree.
+func addGroupFlag(cmd *cobra.Command) {
+	cmd.Flags().String("group-by", "", "Group folders by project, ecosystem or parent, with subtotals")
+}
+
+// groupByOf returns the --group-by grouping, or "" for a flat list.
+func groupByOf(cmd *cobra.Command) (pumu.GroupBy, error) {
+	value, err := cmd.Flags().GetString("group-by")
+	if err != nil || value == "" {
+		return "", err
+	}
+	by, err := pumu.ParseGroupBy(value)
+	if err != nil {
+		return "", fmt.Errorf("--group-by: %w", err)
+	}
+	return by, nil
+}
+
+// sizeWarn and sizeDanger are the byte thresholds used to color folder sizes.
+var (
+	sizeWarn   int64 = 100 * 1024 * 1024
+	sizeDanger int64 = 1000 * 1024 * 1024
+)
+
+func printFolderHeader() {
+	fmt.Println()
+	color.Set(color.FgWhite, color.Underline)
+	fmt.Printf("%-80s | %10s | %s\n", "Folder Path", "Apparent", "Reclaimable")
+	color.Unset()
+}
+
+func printFolderInfo(folder pumu.TargetFolder) {
+	printSizeRow(folderLabel(folder), folder.Size, folder.Apparent)
+}
+
+// folderLabel names a folder in tables and in the selection prompt,
+// counting the folders of an aggregate such as <project>/**/__pycache__.
+func folderLabel(f pumu.TargetFolder) string {
+	return withCount(f.Path, f)
+}
+
+func withCount(label string, f pumu.TargetFolder) string {
+	if f.Members == nil {
+		return label
+	}
+	return fmt.Sprintf("%s (%d folders)", label, len(f.Members))
+}
+
+// printSizeRow prints one row of the folder table, truncating label to fit.
+func printSizeRow(label string, size, apparent int64) {
+	formattedSize := formatSize(size)
+
+	var sizeStr string
+	if size > sizeDanger {
+		sizeStr = color.RedString(fmt.Sprintf("%10s 🚨", formattedSize))
+	} else if size > sizeWarn {
+		sizeStr = color.YellowString(fmt.Sprintf("%10s ⚠️", formattedSize))
+	} else {
+		sizeStr = color.GreenString(fmt.Sprintf("%10s", formattedSize))
+	}
+
+	displayPath := label
+	if len(displayPath) > 80 {
+		displayPath = "..." + displayPath[len(displayPath)-77:]
+	}
+
+	fmt.Printf("%-80s | %10s | %s\n", displayPath, formatSize(apparent), sizeStr)
+}
+
+// printGroups prints the folder table as a tree: each group with its
+// subtotal, followed by its folders.
+func printGroups(groups []pumu.Group, by pumu.GroupBy) {
+	printFolderHeader()
+	for _, g := range groups {
+		printSizeRow(groupLabel(g.Key, g.PM, by), g.Size, g.Apparent)
+		for i, f := range g.Folders {
+			branch := "├─ "
+			if i == len(g.Folders)-1 {
+				branch = "└─ "
+			}
+			printSizeRow("  "+branch+memberLabel(f, g.Key, by), f.Size, f.Apparent)
+		}
+	}
+}
+
+// groupLabel names a group in tables and in the selection prompt.
+func groupLabel(key string, pm pumu.PackageManager, by pumu.GroupBy) string {
+	switch {
+	case by == pumu.GroupByEcosystem && key == "":
+		return "unknown ecosystem"
+	case by == pumu.GroupByEcosystem:
+		return key
+	case pm != "":
+		return fmt.Sprintf("%s (%s)", key, pm)
+	default:
+		return key
+	}
+}
+
+// memberLabel names a folder within its group: its path relative to the
+// group's directory, or its full path when grouping by ecosystem.
+func memberLabel(f pumu.TargetFolder, key string, by pumu.GroupBy) string {
+	if by == pumu.GroupByEcosystem {
+		return folderLabel(f)
+	}
+	if rel, err := filepath.Rel(key, f.Path); err == nil {
+		return withCount(rel, f)
+	}
+	return folderLabel(f)
+}
+
+func printSummary(dryRun bool, folders []pumu.TargetFolder, totalFreed, totalDeleted int64) {
+	var totalApparent int64
+	for _, folder := range folders {
+		totalApparent += folder.Apparent
+	}
+
+	fmt.Println(strings.Repeat("-", 115))
+	if dryRun {
+		color.Green("📋 List complete! Found %d heavy folders.", len(folders))
+		color.Cyan("💾 Total space that can be freed: %s (%s apparent)\n",
+			formatSize(totalFreed), formatSize(totalApparent))
+	} else {
+		color.Green("🧹 Sweep complete! Processed %d heavy folders.", len(folders))
+		color.Cyan("💾 Total space actually freed: %s\n", formatSize(totalDeleted))
+	}
+}
+
+// printInterrupted reports what an interrupted deletion actually removed.
+func printInterrupted(removed, total int, freed int64) {
+	color.Yellow("\n⚠️  Interrupted! Removed %d of %d folders before stopping.", removed, total)
+	color.Cyan("💾 Space actually freed: %s\n", formatSize(freed))
+}
+
+// printReport prints the unverified targets that were held back, the mount
+// points that were not crossed, the directories ignore rules skipped (with
+// --explain-skips) and how many folders the filters left out, if any.
+func printReport(report pumu.Report) {
+	if len(report.SkippedDirs) > 0 {
+		color.Yellow("\nℹ️  Ignore rules skipped %d directories:", len(report.SkippedDirs))
+		for _, d := range report.SkippedDirs {
+			fmt.Println(color.HiBlackString("   %s (%s)", d.Path, d.Rule))
+		}
+	}
+
+	if len(report.SkippedMounts) > 0 {
+		color.Yellow("\nℹ️  Did not cross %d mount points:", len(report.SkippedMounts))
+		for _, m := range report.SkippedMounts {
+			fmt.Println(color.HiBlackString("   %s (%s)", m.Path, m.Reason))
+		}
+		color.Yellow("   Use --one-file-system=false and --include-mounts to scan them.")
+	}
+
+	if len(report.Unverified) > 0 {
+		color.Yellow("\n⚠️  Skipped %d unverified folders (no matching project manifest next to them):", len(report.Unverified))
+		for _, path := range report.Unverified {
+			fmt.Println(color.HiBlackString("   %s", path))
+		}
+		color.Yellow("   Use --include-unverified to include them.")
+	}
+
+	if report.Filtered > 0 {
+		color.Yellow("\nℹ️  %d folders did not match the --min-size, --older-than, --type or --ecosystem filters.", report.Filtered)
+	}
+}
+
+// printPruneHeader prints the header of the prune analysis table.
+func printPruneHeader() {
+	fmt.Println()
+	color.Set(color.FgWhite, color.Underline)
+	fmt.Printf("%-55s | %10s | %11s | %5s | %s\n", "Folder Path", "Apparent", "Reclaimable", "Score", "Reason")
+	color.Unset()
+}
+
+// printPruneRow prints a single row in the prune analysis table.
+func printPruneRow(r pumu.PruneResult, threshold int) {
+	apparentStr := formatSize(r.Apparent)
+	sizeStr := formatSize(r.Size)
+
+	displayPath := folderLabel(r.TargetFolder)
+	if len(displayPath) > 55 {
+		displayPath = "..." + displayPath[len(displayPath)-52:]
+	}
+
+	// Color the score based on value
+	var scoreStr string
+	if r.Score >= 80 {
+		scoreStr = color.RedString("%5d", r.Score)
+	} else if r.Score >= 50 {
+		scoreStr = color.YellowString("%5d", r.Score)
+	} else {
+		scoreStr = color.HiBlackString("%5d", r.Score)
+	}
+
+	// Dim the row if below threshold
+	if r.Score < threshold {
+		fmt.Printf("%-55s | %10s | %11s | %s | %s\n",
+			color.HiBlackString(displayPath),
+			color.HiBlackString(apparentStr),
+			color.HiBlackString(sizeStr),
+			scoreStr,
+			color.HiBlackString(r.Reason),
+		)
+	} else {
+		fmt.Printf("%-55s | %10s | %11s | %s | %s\n",
+			displayPath,
+			apparentStr,
+			sizeStr,
+			scoreStr,
+			r.Reason,
+		)
+	}
+}
+
+// quoteRoots formats roots for progress messages, e.g. 'a', 'b'.
+func quoteRoots(roots []string) string {
+	quoted := make([]string, len(roots))
+	for i, root := range roots {
+		quoted[i] = "'" + root + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// sizeDetail describes a folder's size for the selection prompt, mentioning
+// the apparent size only when hardlinks or sparse files make it differ.
+func sizeDetail(f pumu.TargetFolder) string {
+	if f.Apparent == f.Size {
+		return formatSize(f.Size)
+	}
+	return fmt.Sprintf("%s (%s apparent)", formatSize(f.Size), formatSize(f.Apparent))
+}
+
+// formatSize converts a byte count into a human-readable string (KB, MB, GB, etc.)
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	// "KMGTPE" represents Kilo, Mega, Giga, Tera, Peta, Exa
+	return fmt.Sprintf("%.2f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -1,8 +1,15 @@
 package cmd
 
 import (
-	"pumu/internal/scanner"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
 
+	"pumu/pkg/pumu"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -12,16 +19,23 @@ func init() {
 }
 
 var repairCmd = &cobra.Command{
-	Use:   "repair",
+	Use:   "repair [path...]",
 	Short: "Repair dependency folders",
 	Long:  `Scans for projects with missing or corrupted dependency folders and reinstalls them.`,
 	Example: `  pumu repair                   # repair current directory
   pumu repair --verbose         # show details for healthy projects too
-  pumu repair -p ~/projects     # repair a custom path`,
+  pumu repair -p ~/projects     # repair a custom path
+  pumu repair ~/work ~/oss      # repair several paths
+  pumu repair -o json           # report checks and repairs as JSON`,
+	Args:          cobra.ArbitraryArgs,
 	SilenceErrors: true,
 	SilenceUsage:  true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path, err := cmd.Root().PersistentFlags().GetString("path")
+		roots, err := scanRoots(cmd, args)
+		if err != nil {
+			return err
+		}
+		opts, err := scanOptions(cmd)
 		if err != nil {
 			return err
 		}
@@ -29,6 +43,148 @@ var repairCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return scanner.RepairDir(path, verbose)
+		format, err := outputFormatOf(cmd)
+		if err != nil {
+			return err
+		}
+		if format != outputTable {
+			return writeRepair(cmd.Context(), cmd.OutOrStdout(), format, roots, opts)
+		}
+		return repair(cmd.Context(), roots, opts, verbose)
 	},
 }
+
+// repair scans roots for projects with broken dependencies and repairs them.
+// Set verbose to also report healthy projects. Canceling ctx interrupts the
+// running check or install, skips the remaining projects and returns
+// ctx.Err() after reporting what was repaired.
+func repair(ctx context.Context, roots []string, opts pumu.Options, verbose bool) error {
+	roots = pumu.NormalizeRoots(roots)
+	color.Cyan("🔧 Scanning for projects with broken dependencies in %s...\n", quoteRoots(roots))
+
+	s := pumu.New(opts)
+	projects, report, err := s.FindProjects(ctx, roots)
+	if ctx.Err() != nil {
+		color.Yellow("\n⚠️  Interrupted! The scan did not finish; nothing was repaired.")
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scan projects: %w", err)
+	}
+
+	printReport(report)
+
+	if len(projects) == 0 {
+		color.Green("✨ No projects found!\n")
+		return nil
+	}
+
+	color.Yellow("⏱️  Found %d projects. Checking health...\n", len(projects))
+
+	var repaired, total int
+
+	for _, proj := range projects {
+		result := s.CheckHealth(ctx, proj)
+		if ctx.Err() != nil {
+			break
+		}
+		total++
+
+		if result.Healthy {
+			if verbose {
+				fmt.Printf("\n📁 %s (%s)\n", proj.Dir, proj.PM)
+				color.Green("   ✅ Healthy, skipping.")
+			}
+			continue
+		}
+
+		// Unhealthy project — show issues and repair
+		fmt.Printf("\n📁 %s (%s)\n", proj.Dir, proj.PM)
+		for _, issue := range result.Issues {
+			color.Red("   ❌ %s", issue)
+		}
+
+		// Remove dependency folder
+		targetFolder := filepath.Base(proj.DependencyDir())
+		removed, err := s.RemoveDependencies(proj)
+		if err != nil {
+			color.Red("   ❌ Failed to remove %s: %v", targetFolder, err)
+			continue
+		}
+		if removed {
+			fmt.Printf("   🗑️  Removed %s\n", targetFolder)
+		}
+
+		// Reinstall
+		fmt.Printf("   📦 Reinstalling...\n")
+		err = s.Install(ctx, proj, nil)
+		if ctx.Err() != nil {
+			color.Yellow("   ⚠️  Interrupted while reinstalling.")
+			break
+		}
+		if err != nil {
+			color.Red("   ❌ Failed to reinstall: %v", err)
+			continue
+		}
+
+		color.Green("   ✅ Repaired!")
+		repaired++
+	}
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", 40))
+	if ctx.Err() != nil {
+		color.Yellow("⚠️  Repair interrupted! Fixed %d/%d projects checked (of %d found).", repaired, total, len(projects))
+		return ctx.Err()
+	}
+	color.Green("🔧 Repair complete! Fixed %d/%d projects.", repaired, total)
+
+	return nil
+}
+
+// writeRepair scans roots for projects, repairs the unhealthy ones and writes
+// every checked project to w in format. If ctx is canceled, the projects
+// checked so far are still written before ctx.Err() is returned.
+func writeRepair(ctx context.Context, w io.Writer, format outputFormat, roots []string, opts pumu.Options) error {
+	s := pumu.New(opts)
+	projects, report, err := s.FindProjects(ctx, roots)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scan projects: %w", err)
+	}
+
+	out := repairOutput{scanOutput: newScanOutput("repair", report), Projects: []projectRecord{}}
+	rows := make([][]string, 0, len(projects))
+	for _, proj := range projects {
+		result := s.CheckHealth(ctx, proj)
+		if ctx.Err() != nil {
+			break
+		}
+
+		record := projectRecord{
+			Dir:            proj.Dir,
+			PackageManager: string(proj.PM),
+			Ecosystem:      proj.Ecosystem(),
+			Healthy:        result.Healthy,
+			Issues:         nonNil(result.Issues),
+		}
+		if !result.Healthy {
+			if _, err := s.RemoveDependencies(proj); err != nil {
+				record.Error = fmt.Sprintf("failed to remove %s: %v", filepath.Base(proj.DependencyDir()), err)
+			} else if err := s.Install(ctx, proj, nil); err != nil {
+				record.Error = fmt.Sprintf("failed to reinstall: %v", err)
+			} else {
+				record.Repaired = true
+			}
+		}
+		out.Projects = append(out.Projects, record)
+		rows = append(rows, record.row())
+	}
+
+	if err := writeOutput(w, format, out, projectColumns, rows); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
@@ -2,11 +2,20 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
-	"pumu/internal/scanner"
+	"pumu/internal/config"
+	"pumu/pkg/pumu"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -24,20 +33,34 @@ Running pumu with no subcommand refreshes the current directory.`,
 	Example: `  pumu                        # refresh current directory
   pumu list                   # list all heavy folders
   pumu sweep --no-select      # delete all without prompting`,
-	SilenceErrors: true,
-	SilenceUsage:  true,
+	SilenceErrors:     true,
+	SilenceUsage:      true,
+	PersistentPreRunE: loadConfig,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path, err := cmd.Flags().GetString("path")
+		if err := tableOnly(cmd); err != nil {
+			return err
+		}
+		paths, err := cmd.Flags().GetStringArray("path")
+		if err != nil {
+			return err
+		}
+		opts, err := scanOptions(cmd)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Running refresh in %s...\n", path)
-		return scanner.RefreshCurrentDir()
+		fmt.Printf("Running refresh in %s...\n", strings.Join(paths, ", "))
+		return refreshCurrentDir(cmd.Context(), pumu.New(opts))
 	},
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringP("path", "p", ".", "Root path to scan")
+	rootCmd.PersistentFlags().StringArrayP("path", "p", []string{"."}, "Root path to scan (repeatable)")
+	rootCmd.PersistentFlags().String("config", "", "Config file (default ~/.config/pumu/config.toml)")
+	rootCmd.PersistentFlags().Bool("one-file-system", true, "Don't cross into other filesystems while scanning")
+	rootCmd.PersistentFlags().Bool("include-mounts", false, "Scan network and pseudo filesystems (NFS, SMB, FUSE, ...) when crossing mounts")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Measure every folder instead of reusing cached sizes")
+	rootCmd.PersistentFlags().String("events", "", "Stream progress events to stderr as they happen: ndjson")
+	rootCmd.PersistentFlags().StringP("output", "o", string(outputTable), "Output format: table, json or csv (list, prune and repair)")
 
 	rootCmd.SetVersionTemplate("pumu version {{.Version}}\n")
 
@@ -45,9 +68,193 @@ func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = false
 }
 
-// Execute runs the root command.
+// cfg is the user configuration loaded before any command runs.
+var cfg = config.Default()
+
+// configuredFlags holds the names of the flags loadConfig set from the
+// running command's [commands.<name>] defaults. Such flags are marked
+// changed, like the ones the user set, and this tells the two apart.
+var configuredFlags = map[string]bool{}
+
+// loadConfig reads the config file, applies it to the scanner and fills in
+// any flags the user did not set explicitly from the per-command defaults.
+func loadConfig(cmd *cobra.Command, _ []string) error {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+
+	if path != "" {
+		cfg, err = config.Load(config.ExpandHome(path))
+	} else {
+		cfg, err = config.LoadDefault()
+	}
+	if err != nil {
+		return err
+	}
+
+	sizeWarn = cfg.Size.WarnMB * 1024 * 1024
+	sizeDanger = cfg.Size.DangerMB * 1024 * 1024
+
+	configuredFlags = map[string]bool{}
+	for name, value := range cfg.Commands[cmd.Name()] {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("config: unknown flag %q for command %q", name, cmd.Name())
+		}
+		if flag.Changed {
+			continue
+		}
+		// Arrays set repeatable flags, like several -p values.
+		values, ok := value.([]any)
+		if !ok {
+			values = []any{value}
+		}
+		for _, v := range values {
+			if err := cmd.Flags().Set(name, fmt.Sprint(v)); err != nil {
+				return fmt.Errorf("config: invalid value for %s --%s: %w", cmd.Name(), name, err)
+			}
+		}
+		configuredFlags[name] = true
+	}
+
+	return nil
+}
+
+// scanRoots returns the paths to scan, from the first source that has any:
+// every -p/--path given explicitly plus any positional args, the path of the
+// command's [commands.<name>] defaults, the roots of the config file, and
+// last --path's default.
+func scanRoots(cmd *cobra.Command, args []string) ([]string, error) {
+	paths, err := cmd.Flags().GetStringArray("path")
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []string
+	if cmd.Flags().Changed("path") && !configuredFlags["path"] {
+		roots = append(roots, paths...)
+	}
+	roots = append(roots, args...)
+
+	switch {
+	case len(roots) > 0:
+		return roots, nil
+	case configuredFlags["path"]:
+		return paths, nil
+	case len(cfg.Roots) > 0:
+		return cfg.Roots, nil
+	default:
+		return paths, nil
+	}
+}
+
+// scanOptions returns the scanner options shared by every scanning command:
+// the config file's settings plus the global scan flags.
+func scanOptions(cmd *cobra.Command) (pumu.Options, error) {
+	opts := pumu.Options{
+		Concurrency: cfg.Concurrency,
+		Targets:     applyNameList(pumu.DefaultTargets(), cfg.Targets),
+		IgnoredDirs: applyNameList(pumu.DefaultIgnoredDirs(), cfg.Ignore),
+	}
+	var err error
+
+	if opts.OneFileSystem, err = cmd.Flags().GetBool("one-file-system"); err != nil {
+		return opts, err
+	}
+	if opts.IncludeMounts, err = cmd.Flags().GetBool("include-mounts"); err != nil {
+		return opts, err
+	}
+	if opts.NoCache, err = cmd.Flags().GetBool("no-cache"); err != nil {
+		return opts, err
+	}
+	if opts.OnEvent, err = eventStream(cmd, cmd.ErrOrStderr()); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// applyNameList returns names with list's additions and removals applied.
+func applyNameList(names []string, list config.NameList) []string {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	for _, name := range list.Add {
+		set[name] = true
+	}
+	for _, name := range list.Remove {
+		delete(set, name)
+	}
+
+	out := make([]string, 0, len(set))
+	for name := range set {
+		out = append(out, name)
+	}
+	return out
+}
+
+// refreshCurrentDir detects the package manager in the current directory,
+// removes the dependency folder, and reinstalls dependencies.
+// Canceling ctx interrupts the install.
+func refreshCurrentDir(ctx context.Context, s *pumu.Scanner) error {
+	proj, ok := pumu.ProjectOf(".")
+	if !ok {
+		return fmt.Errorf("could not detect package manager in current directory")
+	}
+
+	fmt.Printf("🔍 Detected package manager: %s\n", proj.PM)
+	if proj.Dir != "." {
+		fmt.Printf("📦 Part of the workspace at %s; refreshing from its root.\n", proj.Dir)
+	}
+
+	if dir := proj.DependencyDir(); dir != "" {
+		targetFolder := filepath.Base(dir)
+		start := time.Now()
+		removed, err := s.RemoveDependencies(proj)
+		if err != nil {
+			return fmt.Errorf("failed to remove %s: %v", targetFolder, err)
+		}
+		if removed {
+			fmt.Printf("✅ Removed %s in %v\n", targetFolder, time.Since(start))
+		} else {
+			fmt.Printf("ℹ️  No %s found, skipping deletion.\n", targetFolder)
+		}
+	}
+
+	fmt.Printf("📦 Running %s install...\n", proj.PM)
+	err := s.Install(ctx, proj, os.Stdout)
+	if ctx.Err() != nil {
+		color.Yellow("\n⚠️  Interrupted! The install did not finish; run pumu again to refresh.")
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to install dependencies: %v", err)
+	}
+
+	fmt.Println("🎉 Refresh complete!")
+	return nil
+}
+
+// Execute runs the root command. The first SIGINT or SIGTERM cancels the
+// command's context, which stops new work and lets in-flight deletions
+// finish; a second one kills pumu right away.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		fmt.Fprintln(os.Stderr, "\nInterrupted, finishing in-flight work... (press Ctrl-C again to quit now)")
+		cancel()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		if errors.Is(err, context.Canceled) {
+			os.Exit(130)
+		}
 		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestScanRootsPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	content := "roots = [\"/srv/all\"]\n\n[commands.sweep]\npath = \"/srv/sweep\"\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	saved := cfg
+	t.Cleanup(func() { cfg, configuredFlags = saved, map[string]bool{} })
+
+	tests := []struct {
+		name    string
+		command string
+		flags   []string
+		args    []string
+		want    []string
+	}{
+		{"command path beats roots", "sweep", nil, nil, []string{"/srv/sweep"}},
+		{"explicit path beats command path", "sweep", []string{"-p", "/tmp/a"}, nil, []string{"/tmp/a"}},
+		{"args beat command path", "sweep", nil, []string{"/tmp/b"}, []string{"/tmp/b"}},
+		{"roots without command path", "list", nil, nil, []string{"/srv/all"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: tt.command}
+			cmd.Flags().StringArrayP("path", "p", []string{"."}, "")
+			cmd.Flags().String("config", "", "")
+			if err := cmd.Flags().Parse(append([]string{"--config", file}, tt.flags...)); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := loadConfig(cmd, tt.args); err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+			roots, err := scanRoots(cmd, tt.args)
+			if err != nil {
+				t.Fatalf("scanRoots() error = %v", err)
+			}
+			if !reflect.DeepEqual(roots, tt.want) {
+				t.Errorf("scanRoots() = %v, want %v", roots, tt.want)
+			}
+		})
+	}
+}
@@ -1,19 +1,27 @@
 package cmd
 
 import (
-	"pumu/internal/scanner"
+	"context"
+	"fmt"
 
+	"pumu/internal/ui"
+	"pumu/pkg/pumu"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	sweepCmd.Flags().Bool("reinstall", false, "Reinstall packages after removing their folders")
 	sweepCmd.Flags().Bool("no-select", false, "Skip interactive selection (delete/reinstall all found folders)")
+	sweepCmd.Flags().Bool("include-unverified", false, "Include folders whose parent has no matching project manifest")
+	addFilterFlags(sweepCmd)
+	addGroupFlag(sweepCmd)
 	rootCmd.AddCommand(sweepCmd)
 }
 
 var sweepCmd = &cobra.Command{
-	Use:   "sweep",
+	Use:   "sweep [path...]",
 	Short: "Sweep (delete) heavy dependency folders",
 	Long: `Scans for heavy dependency folders and removes them.
 Use --reinstall to automatically reinstall packages after deletion,
@@ -21,22 +29,305 @@ and --no-select to skip the interactive selection prompt.`,
 	Example: `  pumu sweep                              # interactive selection
   pumu sweep --no-select                  # delete all without prompting
   pumu sweep --reinstall                  # delete and reinstall
-  pumu sweep --no-select --reinstall ~/projects`,
+  pumu sweep --no-select --reinstall ~/projects
+  pumu sweep --no-select --min-size 1GB --older-than 90d  # safe for cron
+  pumu sweep --group-by project           # select whole projects at once`,
+	Args:          cobra.ArbitraryArgs,
 	SilenceErrors: true,
 	SilenceUsage:  true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path, err := cmd.Root().PersistentFlags().GetString("path")
-		if err != nil {
+		if err := tableOnly(cmd); err != nil {
 			return err
 		}
-		reinstall, err := cmd.Flags().GetBool("reinstall")
+		roots, err := scanRoots(cmd, args)
 		if err != nil {
 			return err
 		}
-		noSelect, err := cmd.Flags().GetBool("no-select")
+		opts, err := scanOptions(cmd)
 		if err != nil {
 			return err
 		}
-		return scanner.SweepDir(path, false, reinstall, noSelect)
+		var run sweepRun
+		if run.reinstall, err = cmd.Flags().GetBool("reinstall"); err != nil {
+			return err
+		}
+		if run.noSelect, err = cmd.Flags().GetBool("no-select"); err != nil {
+			return err
+		}
+		if opts.IncludeUnverified, err = cmd.Flags().GetBool("include-unverified"); err != nil {
+			return err
+		}
+		if opts.Filter, err = filterOptions(cmd, opts.Targets); err != nil {
+			return err
+		}
+		if run.groupBy, err = groupByOf(cmd); err != nil {
+			return err
+		}
+		return run.execute(cmd.Context(), roots, opts)
 	},
 }
+
+// sweepRun holds the settings of a list or sweep run.
+type sweepRun struct {
+	dryRun    bool         // only list, never delete
+	reinstall bool         // reinstall dependencies after deletion
+	noSelect  bool         // skip interactive selection
+	groupBy   pumu.GroupBy // show folders grouped, "" for a flat list
+}
+
+// execute scans roots for heavy dependency folders and deletes them, or only
+// lists them when dryRun is set. Folders are shown as soon as their size is
+// known, while the scan is still running. Canceling ctx stops the scan, lets
+// deletions already under way finish and reports what was actually removed;
+// ctx.Err() is then returned.
+func (r sweepRun) execute(ctx context.Context, roots []string, opts pumu.Options) error {
+	roots = pumu.NormalizeRoots(roots)
+	printScanMessage(r.dryRun, roots)
+
+	var folders []pumu.TargetFolder
+	var err error
+	if !r.dryRun && !r.noSelect {
+		folders, err = r.selectToDelete(ctx, roots, opts)
+	} else {
+		folders, err = r.scanAndPrint(ctx, roots, opts)
+	}
+	if len(folders) == 0 {
+		return err
+	}
+
+	var totalFreed, totalDeleted int64
+	for _, folder := range folders {
+		totalFreed += folder.Size
+	}
+	s := pumu.New(opts)
+	if !r.dryRun {
+		color.Yellow("\n🗑️  Deleting folders concurrently...")
+		res, err := s.Delete(ctx, folders)
+		if err != nil {
+			printInterrupted(len(res.Removed), len(folders), res.Freed)
+			return err
+		}
+		totalDeleted = res.Freed
+	}
+	printSummary(r.dryRun, folders, totalFreed, totalDeleted)
+
+	if !r.dryRun && r.reinstall {
+		reinstallDependencies(ctx, s, folders, r.noSelect)
+	}
+
+	return ctx.Err()
+}
+
+// selectToDelete scans roots while the user selects the folders to delete,
+// then prints the selected ones. It returns no folders, and the error to end
+// the run with, if nothing was found or selected or the selection was canceled.
+func (r sweepRun) selectToDelete(ctx context.Context, roots []string, opts pumu.Options) ([]pumu.TargetFolder, error) {
+	all, folders, report, err := selectFolders(ctx, roots, opts, r.groupBy, "🗑️  Select folders to delete:")
+	if err != nil {
+		return nil, err
+	}
+	if folders == nil {
+		color.Yellow("\n⚠️  Operation canceled.")
+		return nil, ctx.Err()
+	}
+	printReport(report)
+	if len(all) == 0 {
+		color.Green("✨ No heavy folders found!\n")
+		return nil, nil
+	}
+	if len(folders) == 0 {
+		color.Green("\n✨ No folders selected for deletion.\n")
+		return nil, nil
+	}
+	r.printFolders(folders, roots)
+	return folders, nil
+}
+
+// scanAndPrint scans roots and prints every folder found: as soon as it is
+// sized, or once the scan is done when grouped. It returns no folders, and
+// the error to end the run with, if the scan failed or found nothing.
+func (r sweepRun) scanAndPrint(ctx context.Context, roots []string, opts pumu.Options) ([]pumu.TargetFolder, error) {
+	var folders []pumu.TargetFolder
+	var report pumu.Report
+	var err error
+	if r.groupBy == "" {
+		folders, report, err = scanAsFound(ctx, roots, opts)
+	} else {
+		// Groups need every folder, so they are printed once the scan is done.
+		folders, report, err = pumu.New(opts).Scan(ctx, roots)
+	}
+	if ctx.Err() != nil {
+		color.Yellow("\n⚠️  Interrupted! The scan did not finish; nothing was deleted.")
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	printReport(report)
+	if len(folders) == 0 {
+		color.Green("✨ No heavy folders found!\n")
+		return nil, nil
+	}
+	if r.groupBy != "" {
+		r.printFolders(folders, roots)
+	}
+	return folders, nil
+}
+
+// printFolders prints the folder table, grouped if groupBy is set.
+func (r sweepRun) printFolders(folders []pumu.TargetFolder, roots []string) {
+	if r.groupBy != "" {
+		printGroups(pumu.GroupFolders(folders, r.groupBy, roots), r.groupBy)
+		return
+	}
+	printFolderHeader()
+	for _, folder := range folders {
+		printFolderInfo(folder)
+	}
+}
+
+func printScanMessage(dryRun bool, roots []string) {
+	if dryRun {
+		color.Cyan("🔎 Listing heavy dependency folders in %s...\n", quoteRoots(roots))
+	} else {
+		color.Cyan("🔎 Scanning for heavy dependency folders in %s...\n", quoteRoots(roots))
+	}
+	color.Yellow("⏱️  Calculating sizes concurrently as folders are found...")
+}
+
+// scanAsFound scans roots, printing a table row for each folder as soon as
+// its size is known.
+func scanAsFound(ctx context.Context, roots []string, opts pumu.Options) ([]pumu.TargetFolder, pumu.Report, error) {
+	var shown int
+	opts = withEvents(opts, func(e pumu.Event) {
+		if e.Kind != pumu.FolderSized {
+			return
+		}
+		if shown == 0 {
+			printFolderHeader()
+		}
+		printFolderInfo(e.Folder)
+		shown++
+	})
+	return pumu.New(opts).Scan(ctx, roots)
+}
+
+// selectFolders scans roots while presenting an interactive multi-select that
+// fills in as sized folders arrive. If groupBy is set, folders are listed
+// under a header per group that selects the whole group. It returns every
+// folder found and the ones selected; selected is nil if the user canceled.
+func selectFolders(ctx context.Context, roots []string, opts pumu.Options, groupBy pumu.GroupBy, title string) (
+	all, selected []pumu.TargetFolder, report pumu.Report, err error,
+) {
+	items := make(chan ui.Item)
+	var found []pumu.TargetFolder
+	var scanReport pumu.Report
+	var scanErr error
+
+	// found, scanReport and scanErr are only read after items is closed,
+	// which happens once the scan has returned.
+	opts = withEvents(opts, func(e pumu.Event) {
+		if e.Kind != pumu.FolderSized {
+			return
+		}
+		item := ui.Item{
+			Label:    folderLabel(e.Folder),
+			Detail:   sizeDetail(e.Folder),
+			Selected: true,
+			ID:       len(found),
+		}
+		if groupBy != "" {
+			key, pm := pumu.GroupKey(e.Folder, groupBy, roots)
+			item.Group = groupLabel(key, pm, groupBy)
+			item.Label = memberLabel(e.Folder, key, groupBy)
+		}
+		found = append(found, e.Folder)
+		items <- item
+	})
+	go func() {
+		defer close(items)
+		_, scanReport, scanErr = pumu.New(opts).Scan(ctx, roots)
+	}()
+
+	result, err := ui.RunMultiSelectStream(ctx, title, items)
+	if err != nil {
+		return nil, nil, pumu.Report{}, fmt.Errorf("selection failed: %w", err)
+	}
+	if result.Canceled {
+		return nil, nil, pumu.Report{}, nil
+	}
+	if scanErr != nil {
+		return nil, nil, pumu.Report{}, scanErr
+	}
+
+	selected = make([]pumu.TargetFolder, 0, len(result.Items))
+	for _, item := range result.Items {
+		if item.Selected {
+			selected = append(selected, found[item.ID])
+		}
+	}
+	return found, selected, scanReport, nil
+}
+
+// reinstallDependencies reinstalls the projects the folders belonged to, one
+// at a time. Canceling ctx interrupts the running install and skips the rest.
+func reinstallDependencies(ctx context.Context, s *pumu.Scanner, folders []pumu.TargetFolder, noSelect bool) {
+	targets := pumu.ProjectsOf(folders)
+	if len(targets) == 0 {
+		color.Yellow("\n⚠️  No projects with known package managers found for reinstallation.")
+		return
+	}
+
+	// Interactive selection for reinstallation
+	if !noSelect {
+		items := make([]ui.Item, len(targets))
+		for i, t := range targets {
+			items[i] = ui.Item{
+				Label:    t.Dir,
+				Detail:   string(t.PM),
+				Selected: true,
+			}
+		}
+
+		result, err := ui.RunMultiSelect(ctx, "📦 Select projects to reinstall:", items)
+		if err != nil {
+			color.Red("❌ Selection failed: %v", err)
+			return
+		}
+		if result.Canceled {
+			color.Yellow("\n⚠️  Reinstallation canceled.")
+			return
+		}
+
+		// Filter to only selected targets
+		var selected []pumu.Project
+		for i, item := range result.Items {
+			if item.Selected {
+				selected = append(selected, targets[i])
+			}
+		}
+		targets = selected
+	}
+
+	if len(targets) == 0 {
+		color.Green("\n✨ No projects selected for reinstallation.")
+		return
+	}
+
+	color.Yellow("\n⚙️  Reinstalling dependencies sequentially...")
+	for i, t := range targets {
+		fmt.Printf("📦 Reinstalling for %s (%s)...\n", t.Dir, t.PM)
+		err := s.Install(ctx, t, nil)
+		if ctx.Err() != nil {
+			color.Yellow("\n⚠️  Interrupted! %s was not fully reinstalled; skipped %d more projects.",
+				t.Dir, len(targets)-i-1)
+			return
+		}
+		if err != nil {
+			color.Red("❌ Failed to reinstall %s: %v", t.Dir, err)
+		} else {
+			color.Green("✅ Reinstalled %s", t.Dir)
+		}
+	}
+	color.Green("🎉 All target reinstallations complete!")
+}
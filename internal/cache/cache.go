@@ -0,0 +1,200 @@
+// Package cache keeps an on-disk index of folder sizes, so that repeat scans
+// can skip walking folders that have not changed since they were measured.
+package cache
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileName is the name of the index file inside the cache directory.
+const FileName = "sizes.json"
+
+// version is bumped whenever the meaning of an entry changes, which makes
+// older index files be discarded instead of misread.
+const version = 1
+
+// Entry is what the index remembers about one folder.
+type Entry struct {
+	Apparent    int64  `json:"apparent"`
+	Reclaimable int64  `json:"reclaimable"`
+	Files       int64  `json:"files"`
+	Signature   string `json:"signature"`
+}
+
+type indexFile struct {
+	Version int              `json:"version"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// Index maps absolute folder paths to their last measured size.
+// It is safe for concurrent use.
+type Index struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	dirty   bool
+}
+
+// DefaultDir returns pumu's cache directory,
+// $XDG_CACHE_HOME/pumu or ~/.cache/pumu.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "pumu"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "pumu"), nil
+}
+
+// DefaultPath returns the location of the size index inside DefaultDir.
+func DefaultPath() (string, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Open reads the index at path. A missing, unreadable or outdated index is
+// not an error: the cache only ever saves work, so it starts out empty.
+func Open(path string) *Index {
+	idx := &Index{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is pumu's own cache file
+	if err != nil {
+		return idx
+	}
+	var f indexFile
+	if json.Unmarshal(data, &f) != nil || f.Version != version || f.Entries == nil {
+		return idx
+	}
+	idx.entries = f.Entries
+	return idx
+}
+
+// Lookup returns the entry for path if it was stored with the same signature.
+func (idx *Index) Lookup(path, signature string) (Entry, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	e, ok := idx.entries[path]
+	if !ok || e.Signature != signature {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Store records e for path, replacing any previous entry.
+func (idx *Index) Store(path string, e Entry) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.entries[path] = e
+	idx.dirty = true
+}
+
+// Save writes the index back to disk if anything was stored, dropping entries
+// for folders that no longer exist. The file is replaced atomically, so a
+// concurrent pumu run never reads a half-written index.
+func (idx *Index) Save() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.dirty {
+		return nil
+	}
+	for path := range idx.entries {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			delete(idx.entries, path)
+		}
+	}
+
+	data, err := json.Marshal(indexFile{Version: version, Entries: idx.entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(idx.path), FileName+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), idx.path); err != nil {
+		return err
+	}
+
+	idx.dirty = false
+	return nil
+}
+
+// Clear removes the index at path. A missing index is not an error.
+func Clear(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Signature returns a cheap fingerprint of dir's state: the modification time
+// of dir itself, the name, size and modification time of each of its
+// immediate children, and the modification time of each file in extra (such
+// as the project's lockfile). Changes deeper inside dir that touch none of
+// these go unnoticed; that is the price of not walking the folder.
+func Signature(dir string, extra ...string) (string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(dir) // sorted, so the hash is stable
+	if err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	writeInt(h, info.ModTime().UnixNano())
+	for _, e := range entries {
+		child, err := e.Info()
+		if err != nil {
+			continue
+		}
+		_, _ = io.WriteString(h, e.Name())
+		writeInt(h, child.Size())
+		writeInt(h, child.ModTime().UnixNano())
+	}
+	for _, path := range extra {
+		_, _ = io.WriteString(h, path)
+		if st, err := os.Stat(path); err == nil {
+			writeInt(h, st.ModTime().UnixNano())
+		}
+	}
+
+	return fmt.Sprintf("%016x", h.Sum64()), nil
+}
+
+func writeInt(w io.Writer, v int64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(v)) //nolint:gosec // only hashed, sign does not matter
+	_, _ = w.Write(buf[:])
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIndexRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	folder := filepath.Join(dir, "node_modules")
+	if err := os.Mkdir(folder, 0o750); err != nil {
+		t.Fatalf("failed to create folder: %v", err)
+	}
+	path := filepath.Join(dir, "cache", FileName)
+
+	idx := Open(path)
+	idx.Store(folder, Entry{Apparent: 10, Reclaimable: 8, Files: 2, Signature: "abc"})
+	idx.Store(filepath.Join(dir, "gone"), Entry{Signature: "abc"})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	idx = Open(path)
+	e, ok := idx.Lookup(folder, "abc")
+	if !ok || e.Reclaimable != 8 || e.Files != 2 {
+		t.Errorf("Lookup() = %+v, %v, want stored entry", e, ok)
+	}
+	if _, ok := idx.Lookup(folder, "other"); ok {
+		t.Error("Lookup() with a changed signature should miss")
+	}
+	if _, ok := idx.Lookup(filepath.Join(dir, "gone"), "abc"); ok {
+		t.Error("Save() should drop entries for folders that no longer exist")
+	}
+
+	if err := Clear(path); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if err := Clear(path); err != nil {
+		t.Errorf("Clear() on a missing index error = %v", err)
+	}
+	if _, ok := Open(path).Lookup(folder, "abc"); ok {
+		t.Error("Lookup() after Clear() should miss")
+	}
+}
+
+func TestOpenCorruptIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	idx := Open(path)
+	idx.Store("/x", Entry{Signature: "s"})
+	if _, ok := idx.Lookup("/x", "s"); !ok {
+		t.Error("a corrupt index should start out empty and stay usable")
+	}
+}
+
+func TestSignature(t *testing.T) {
+	dir := t.TempDir()
+	folder := filepath.Join(dir, "node_modules")
+	lockfile := filepath.Join(dir, "package-lock.json")
+	for _, p := range []string{folder, filepath.Join(folder, "a")} {
+		if err := os.Mkdir(p, 0o750); err != nil {
+			t.Fatalf("failed to create %s: %v", p, err)
+		}
+	}
+	if err := os.WriteFile(lockfile, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("failed to write lockfile: %v", err)
+	}
+
+	sig := func() string {
+		t.Helper()
+		s, err := Signature(folder, lockfile)
+		if err != nil {
+			t.Fatalf("Signature() error = %v", err)
+		}
+		return s
+	}
+
+	base := sig()
+	if again := sig(); again != base {
+		t.Fatalf("Signature() is not stable: %s != %s", again, base)
+	}
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(lockfile, later, later); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	if sig() == base {
+		t.Error("Signature() did not change when the lockfile changed")
+	}
+	base = sig()
+	if err := os.Chtimes(filepath.Join(folder, "a"), later, later); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	if sig() == base {
+		t.Error("Signature() did not change when a child changed")
+	}
+}
@@ -0,0 +1,132 @@
+// Package config loads pumu's user-level configuration file, which lets users
+// change scan targets, ignored directories, default roots and per-command
+// flag defaults without forking.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds every user-tunable setting read from config.toml.
+type Config struct {
+	// Roots are the paths scanned when --path is not given.
+	Roots []string `toml:"roots"`
+	// Concurrency bounds the number of concurrent size, analysis and delete
+	// operations. Zero leaves it to pumu.DefaultConcurrency.
+	Concurrency int        `toml:"concurrency"`
+	Targets     NameList   `toml:"targets"`
+	Ignore      NameList   `toml:"ignore"`
+	Size        SizeLimits `toml:"size"`
+	// Commands maps a command name (e.g. "prune") to flag defaults for it.
+	Commands map[string]map[string]any `toml:"commands"`
+}
+
+// NameList adds folder names to, or removes them from, a built-in set.
+type NameList struct {
+	Add    []string `toml:"add"`
+	Remove []string `toml:"remove"`
+}
+
+// SizeLimits sets the size thresholds (in MB) used to color folder sizes.
+type SizeLimits struct {
+	WarnMB   int64 `toml:"warn_mb"`
+	DangerMB int64 `toml:"danger_mb"`
+}
+
+// Default returns the configuration used when no config file exists.
+func Default() *Config {
+	return &Config{
+		Size: SizeLimits{
+			WarnMB:   100,
+			DangerMB: 1000,
+		},
+	}
+}
+
+// DefaultPath returns the location of the user config file,
+// $XDG_CONFIG_HOME/pumu/config.toml or ~/.config/pumu/config.toml.
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "pumu", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "pumu", "config.toml"), nil
+}
+
+// LoadDefault reads the config file at DefaultPath.
+// A missing file is not an error and yields Default().
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		// No home directory means there is no user config to read.
+		return Default(), nil
+	}
+	cfg, err := Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	return cfg, err
+}
+
+// Load reads and validates the config file at path. Settings that are
+// absent from the file keep their Default() values.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return nil, fmt.Errorf("invalid config %s: unknown keys: %s", path, strings.Join(keys, ", "))
+	}
+
+	if err := cfg.validate(md); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	for i, root := range cfg.Roots {
+		cfg.Roots[i] = ExpandHome(root)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) validate(md toml.MetaData) error {
+	if md.IsDefined("concurrency") && c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", c.Concurrency)
+	}
+	if c.Size.WarnMB < 0 || c.Size.DangerMB < c.Size.WarnMB {
+		return fmt.Errorf("size thresholds must satisfy 0 <= warn_mb <= danger_mb")
+	}
+	return nil
+}
+
+// ExpandHome replaces a leading "~" in path with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
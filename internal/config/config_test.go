@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+roots = ["/srv/code"]
+concurrency = 8
+
+[targets]
+add = ["out"]
+remove = ["build"]
+
+[ignore]
+add = ["secrets"]
+
+[commands.prune]
+threshold = 70
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Concurrency != 8 {
+		t.Errorf("Concurrency = %d, want 8", cfg.Concurrency)
+	}
+	if len(cfg.Roots) != 1 || cfg.Roots[0] != "/srv/code" {
+		t.Errorf("Roots = %v, want [/srv/code]", cfg.Roots)
+	}
+	if len(cfg.Targets.Add) != 1 || cfg.Targets.Add[0] != "out" {
+		t.Errorf("Targets.Add = %v, want [out]", cfg.Targets.Add)
+	}
+	if len(cfg.Targets.Remove) != 1 || cfg.Targets.Remove[0] != "build" {
+		t.Errorf("Targets.Remove = %v, want [build]", cfg.Targets.Remove)
+	}
+	if got := cfg.Commands["prune"]["threshold"]; got != int64(70) {
+		t.Errorf("Commands[prune][threshold] = %v, want 70", got)
+	}
+	// Unset values keep their defaults
+	if cfg.Size.DangerMB != 1000 {
+		t.Errorf("Size.DangerMB = %d, want default 1000", cfg.Size.DangerMB)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown key", `concurency = 4`},
+		{"zero concurrency", `concurrency = 0`},
+		{"inverted size limits", "[size]\nwarn_mb = 500\ndanger_mb = 100"},
+		{"malformed toml", `roots = [`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writeConfig(t, tt.content)); err == nil {
+				t.Errorf("Load() expected error for %q", tt.content)
+			}
+		})
+	}
+}
+
+func TestLoadDefaultMissingFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := LoadDefault()
+	if err != nil {
+		t.Fatalf("LoadDefault() error = %v", err)
+	}
+	if cfg.Concurrency != Default().Concurrency {
+		t.Errorf("Concurrency = %d, want default %d", cfg.Concurrency, Default().Concurrency)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"~", home},
+		{"~/code", filepath.Join(home, "code")},
+		{"/abs/path", "/abs/path"},
+		{"~other/code", "~other/code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := ExpandHome(tt.path); got != tt.expected {
+				t.Errorf("ExpandHome(%s) = %s, want %s", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Package ignore implements gitignore-style matching for .pumuignore files,
+// letting a repository declare directories that pumu must never touch.
+package ignore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// FileName is the name of the per-directory ignore file.
+const FileName = ".pumuignore"
+
+// pattern is a single compiled line of an ignore file.
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	text    string // the line as written
+}
+
+// Matcher holds the patterns of one ignore file. Patterns are matched
+// against paths relative to the directory the file sits in.
+type Matcher struct {
+	dir      string
+	patterns []pattern
+}
+
+// Parse reads gitignore-style patterns from r for the ignore file in dir.
+// Invalid patterns are skipped, as git does.
+func Parse(dir string, r io.Reader) (*Matcher, error) {
+	m := &Matcher{dir: dir}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if p, ok := parseLine(sc.Text()); ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+
+	return m, sc.Err()
+}
+
+// match reports whether rel (slash-separated, relative to the matcher's dir)
+// is ignored, and whether any pattern matched at all. The last matching
+// pattern wins, so later negations re-include earlier exclusions.
+func (m *Matcher) match(rel string, isDir bool) (ignored, matched bool) {
+	p := m.last(rel, isDir)
+	if p == nil {
+		return false, false
+	}
+	return !p.negate, true
+}
+
+// last returns the last pattern matching rel, or nil if none does.
+func (m *Matcher) last(rel string, isDir bool) *pattern {
+	var last *pattern
+	for i, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(rel) {
+			last = &m.patterns[i]
+		}
+	}
+	return last
+}
+
+func parseLine(line string) (pattern, bool) {
+	line = strings.TrimRight(line, "\r")
+	line = trimTrailingSpaces(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+
+	p := pattern{text: line}
+	switch {
+	case strings.HasPrefix(line, "!"):
+		p.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern{}, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to the ignore file's directory.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return pattern{}, false
+	}
+	p.re = re
+	return p, true
+}
+
+// trimTrailingSpaces removes trailing spaces unless they are escaped with a backslash.
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// globToRegexp translates a gitignore glob into a regular expression body.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			i += writeStar(&b, glob, i)
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := classEnd(glob, i)
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : end]
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
+
+// writeStar writes the expression for the '*' at glob[i] and returns how many
+// extra bytes of glob it consumed. "**" only has special meaning as a whole
+// path segment: a leading "**/" matches any number of directories and a
+// trailing "/**" matches everything inside.
+func writeStar(b *strings.Builder, glob string, i int) int {
+	if i+1 >= len(glob) || glob[i+1] != '*' {
+		b.WriteString("[^/]*")
+		return 0
+	}
+
+	segmentStart := i == 0 || glob[i-1] == '/'
+	next := i + 2
+	switch {
+	case segmentStart && next == len(glob):
+		b.WriteString(".*")
+		return 1
+	case segmentStart && glob[next] == '/':
+		b.WriteString("(?:.*/)?")
+		return 2
+	default:
+		b.WriteString("[^/]*")
+		return 1
+	}
+}
+
+// classEnd returns the index of the ']' closing the bracket expression
+// starting at glob[start], or -1 if it is unterminated.
+func classEnd(glob string, start int) int {
+	j := start + 1
+	if j < len(glob) && glob[j] == '!' {
+		j++
+	}
+	if j < len(glob) && glob[j] == ']' {
+		j++
+	}
+	end := strings.IndexByte(glob[j:], ']')
+	if end < 0 {
+		return -1
+	}
+	return j + end
+}
+
+// Tree answers whether a path below root is excluded by any .pumuignore file
+// in its ancestry, including files above root. Ignore files are loaded lazily
+// and cached, and Tree is safe for concurrent use.
+type Tree struct {
+	root    string
+	absRoot string
+
+	mu     sync.Mutex
+	chains map[string][]*Matcher // absolute dir -> ignore files applying to its entries
+}
+
+// NewTree returns a Tree for paths found while walking root.
+func NewTree(root string) *Tree {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		absRoot = root
+	}
+	return &Tree{
+		root:    root,
+		absRoot: absRoot,
+		chains:  make(map[string][]*Matcher),
+	}
+}
+
+// Ignored reports whether path, which must be root or lie below it, is
+// excluded. Deeper ignore files take precedence over shallower ones.
+func (t *Tree) Ignored(path string, isDir bool) bool {
+	_, ignored := t.Rule(path, isDir)
+	return ignored
+}
+
+// Rule is like Ignored but also returns the rule that decided, as
+// "<ignore file>: <pattern>", or "" if no pattern matched.
+func (t *Tree) Rule(path string, isDir bool) (rule string, ignored bool) {
+	abs := t.absPath(path)
+
+	for _, m := range t.chain(filepath.Dir(abs)) {
+		rel, err := filepath.Rel(m.dir, abs)
+		if err != nil {
+			continue
+		}
+		if p := m.last(filepath.ToSlash(rel), isDir); p != nil {
+			rule, ignored = filepath.Join(m.dir, FileName)+": "+p.text, !p.negate
+		}
+	}
+
+	return rule, ignored
+}
+
+func (t *Tree) absPath(path string) string {
+	rel, err := filepath.Rel(t.root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.Join(t.absRoot, rel)
+}
+
+// chain returns the ignore files that apply to the entries of dir,
+// outermost first.
+func (t *Tree) chain(dir string) []*Matcher {
+	t.mu.Lock()
+	c, ok := t.chains[dir]
+	t.mu.Unlock()
+	if ok {
+		return c
+	}
+
+	if parent := filepath.Dir(dir); parent != dir {
+		c = t.chain(parent)
+	}
+	if m := loadMatcher(dir); m != nil {
+		c = append(c[:len(c):len(c)], m)
+	}
+
+	t.mu.Lock()
+	t.chains[dir] = c
+	t.mu.Unlock()
+	return c
+}
+
+// loadMatcher parses the ignore file in dir, or returns nil if there is none.
+func loadMatcher(dir string) *Matcher {
+	f, err := os.Open(filepath.Join(dir, FileName)) //nolint:gosec // reading ignore files is the point
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	m, err := Parse(dir, f)
+	if err != nil {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,120 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatcherMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"basename anywhere", "dist", "a/b/dist", true, true},
+		{"basename at top", "dist", "dist", true, true},
+		{"no partial name match", "dist", "distro", true, false},
+		{"anchored path", "tools/build", "tools/build", true, true},
+		{"anchored path not nested", "tools/build", "x/tools/build", true, false},
+		{"leading slash anchors", "/build", "build", true, true},
+		{"leading slash not nested", "/build", "app/build", true, false},
+		{"dir only matches dir", "cache/", "cache", true, true},
+		{"dir only skips file", "cache/", "cache", false, false},
+		{"star in segment", "*.egg-info", "pkg/foo.egg-info", true, true},
+		{"star does not cross slash", "a/*/c", "a/b/x/c", true, false},
+		{"leading double star", "**/generated", "x/y/generated", true, true},
+		{"leading double star at top", "**/generated", "generated", true, true},
+		{"middle double star", "a/**/c", "a/c", true, true},
+		{"middle double star deep", "a/**/c", "a/b/x/c", true, true},
+		{"trailing double star", "vendor/**", "vendor/lib", true, true},
+		{"trailing double star excludes dir itself", "vendor/**", "vendor", true, false},
+		{"question mark", "build?", "build2", true, true},
+		{"char class", "build[0-9]", "build7", true, true},
+		{"negated char class", "build[!0-9]", "build7", true, false},
+		{"negation re-includes", "dist\n!app/dist", "app/dist", true, false},
+		{"negation keeps others", "dist\n!app/dist", "lib/dist", true, true},
+		{"later pattern wins", "!dist\ndist", "dist", true, true},
+		{"comment ignored", "# dist", "dist", true, false},
+		{"escaped hash", `\#notes`, "#notes", true, true},
+		{"escaped bang", `\!important`, "!important", true, true},
+		{"trailing spaces trimmed", "dist   ", "dist", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse("/repo", strings.NewReader(tt.patterns))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, _ := m.match(tt.path, tt.isDir)
+			if got != tt.expected {
+				t.Errorf("match(%q) with %q = %v, want %v", tt.path, tt.patterns, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTreeIgnored(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"app/dist", "app/tools/build", "lib/dist", "lib/src"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o750); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+	}
+
+	writeIgnore := func(dir, content string) {
+		path := filepath.Join(root, dir, FileName)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	writeIgnore(".", "dist\n")
+	writeIgnore("lib", "!dist\nsrc/\n")
+	writeIgnore("app", "/tools/build\n")
+
+	tree := NewTree(root)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"app/dist", true},
+		{"app/tools/build", true},
+		{"lib/dist", false}, // deeper negation overrides the root file
+		{"lib/src", true},
+		{"app", false},
+		{".", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := tree.Ignored(filepath.Join(root, tt.path), true)
+			if got != tt.expected {
+				t.Errorf("Ignored(%s) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTreeRule(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "app", "dist"), 0o750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte("# build output\ndist/\n"), 0o600); err != nil {
+		t.Fatalf("failed to write ignore file: %v", err)
+	}
+
+	tree := NewTree(root)
+	rule, ignored := tree.Rule(filepath.Join(root, "app", "dist"), true)
+	if want := filepath.Join(root, FileName) + ": dist/"; !ignored || rule != want {
+		t.Errorf("Rule(app/dist) = %q, %v, want %q, true", rule, ignored, want)
+	}
+	if rule, ignored := tree.Rule(filepath.Join(root, "app"), true); ignored || rule != "" {
+		t.Errorf("Rule(app) = %q, %v, want \"\", false", rule, ignored)
+	}
+}
@@ -3,9 +3,9 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"time"
 )
@@ -14,32 +14,32 @@ import (
 type PruneResult struct {
 	Path         string
 	Reason       string // Human readable explanation
-	Size         int64
-	Score        int  // 0-100, higher = safer to delete
-	SafeToDelete bool // Whether score meets the threshold
+	Size         int64  // reclaimable bytes on disk
+	Score        int    // 0-100, higher = safer to delete
+	SafeToDelete bool   // Whether score meets the threshold
 }
 
 // AnalyzeFolder evaluates whether a dependency/build folder is safe to prune
 // based on multiple heuristics: orphan status, build cache, lockfile staleness,
-// and uncommitted changes.
-func AnalyzeFolder(folderPath string, size int64) PruneResult {
+// and uncommitted changes. projectDir is the project the folder belongs to:
+// its parent, or the workspace root for a member package, and pm the package
+// manager that installs the folder there, or Unknown if there is none.
+func AnalyzeFolder(ctx context.Context, folderPath, projectDir string, pm PackageManager, size int64) PruneResult {
 	result := PruneResult{
 		Path: folderPath,
 		Size: size,
 	}
 
 	folderName := filepath.Base(folderPath)
-	projectDir := filepath.Dir(folderPath)
 
-	// Build output folders are always re-generable - heuristic 1
-	if isBuildCache(folderName) {
+	// Build output folders and tagged caches are always re-generable - heuristic 1
+	if isBuildCache(folderName) || HasCacheTag(folderPath) {
 		result.Score = 90
 		result.Reason = "🟢 Build cache (re-generable)"
 		return result
 	}
 
 	// Check if project has a lockfile at all - heuristic 2
-	pm := DetectManager(projectDir)
 	if pm == Unknown {
 		result.Score = 95
 		result.Reason = "🔴 No lockfile (orphan folder)"
@@ -47,7 +47,7 @@ func AnalyzeFolder(folderPath string, size int64) PruneResult {
 	}
 
 	// Check lockfile age (staleness) - heuristic 3
-	lockfileAge := getLockfileAge(projectDir, pm)
+	lockfileAge := LockfileAge(projectDir, pm)
 	if lockfileAge > 0 {
 		days := int(lockfileAge.Hours() / 24)
 
@@ -64,7 +64,7 @@ func AnalyzeFolder(folderPath string, size int64) PruneResult {
 	}
 
 	// Check git status for uncommitted lockfile changes - heuristic 4
-	if hasUncommittedLockfileChanges(projectDir) {
+	if hasUncommittedLockfileChanges(ctx, projectDir) {
 		result.Score = 15
 		result.Reason = "⚪ Uncommitted lockfile changes (active work)"
 		return result
@@ -85,19 +85,14 @@ func AnalyzeFolder(folderPath string, size int64) PruneResult {
 
 // isBuildCache returns true for folders that are purely build output.
 func isBuildCache(name string) bool {
-	caches := []string{".next", ".svelte-kit", "dist", "build"}
-	for _, c := range caches {
-		if name == c {
-			return true
-		}
-	}
-	return false
+	entries := TargetsNamed(name)
+	return len(entries) > 0 && entries[0].Kind == KindCache
 }
 
-// getLockfileAge returns the age of the lockfile for a project.
+// LockfileAge returns the age of the lockfile for a project.
 // Returns 0 if no lockfile found.
-func getLockfileAge(dir string, pm PackageManager) time.Duration {
-	lockfiles := getLockfiles(pm)
+func LockfileAge(dir string, pm PackageManager) time.Duration {
+	lockfiles := Lockfiles(pm)
 
 	for _, lf := range lockfiles {
 		path := filepath.Join(dir, lf)
@@ -110,33 +105,47 @@ func getLockfileAge(dir string, pm PackageManager) time.Duration {
 	return 0
 }
 
-// getLockfiles returns the lockfile names for a given package manager.
-func getLockfiles(pm PackageManager) []string {
-	switch pm {
-	case Npm:
-		return []string{"package-lock.json"}
-	case Pnpm:
-		return []string{"pnpm-lock.yaml"}
-	case Yarn:
-		return []string{"yarn.lock"}
-	case Bun:
-		return []string{"bun.lockb", "bun.lock"}
-	case Deno:
-		return []string{"deno.lock"}
-	case Cargo:
-		return []string{"Cargo.lock"}
-	case Go:
-		return []string{"go.sum"}
-	case Pip:
-		return []string{"requirements.txt", "pyproject.toml"}
-	default:
-		return nil
-	}
+// Lockfiles returns the lockfile names for a given package manager.
+func Lockfiles(pm PackageManager) []string {
+	return lockfiles[pm]
+}
+
+// lockfiles lists the lockfiles of each package manager, most telling first.
+var lockfiles = map[PackageManager][]string{
+	Npm:   {"package-lock.json"},
+	Pnpm:  {"pnpm-lock.yaml"},
+	Yarn:  {"yarn.lock"},
+	Bun:   {"bun.lockb", "bun.lock"},
+	Deno:  {"deno.lock"},
+	Cargo: {"Cargo.lock"},
+	Go:    {"go.sum"},
+	Pip:   {"requirements.txt", "pyproject.toml"},
+	// Maven has no lockfile; the POM pins the dependency versions.
+	Maven: {"pom.xml"},
+	// Dependency locking is opt-in, so fall back to the version catalog
+	// and the build scripts that declare dependencies.
+	Gradle: {
+		"gradle.lockfile", "gradle/libs.versions.toml",
+		"build.gradle.kts", "build.gradle", "settings.gradle.kts", "settings.gradle",
+	},
+	Composer: {"composer.lock"},
+	Bundler:  {"Gemfile.lock"},
+	Uv:       {"uv.lock"},
+	Poetry:   {"poetry.lock"},
+	Pipenv:   {"Pipfile.lock"},
+	Pdm:      {"pdm.lock"},
+	Conda:    {"conda-lock.yml", "environment.yml", "environment.yaml"},
+	Dotnet:   {"packages.lock.json"},
+	Mix:      {"mix.lock"},
+	Pub:      {"pubspec.lock"},
+	Stack:    {"stack.yaml.lock"},
+	// Cabal only pins versions in a freeze file, if the project has one.
+	Cabal: {"cabal.project.freeze", "cabal.project"},
 }
 
 // hasUncommittedLockfileChanges checks if git reports uncommitted changes
 // in the project directory.
-func hasUncommittedLockfileChanges(dir string) bool {
+func hasUncommittedLockfileChanges(ctx context.Context, dir string) bool {
 	// Quick check: is this even a git repo?
 	gitDir := filepath.Join(dir, ".git")
 	if _, err := os.Stat(gitDir); err != nil {
@@ -156,7 +165,7 @@ func hasUncommittedLockfileChanges(dir string) bool {
 	}
 
 	// Use git status to check for uncommitted changes in lockfiles
-	cmd := execCommand("git", "status", "--porcelain", dir)
+	cmd := execCommand(ctx, "git", "status", "--porcelain", dir)
 	cmd.Dir = dir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -181,5 +190,5 @@ func formatDays(days int) string {
 	return fmt.Sprintf("~%d months", months)
 }
 
-// execCommand is a wrapper around exec.Command for testability.
-var execCommand = exec.Command
+// execCommand is a wrapper around commandContext for testability.
+var execCommand = commandContext
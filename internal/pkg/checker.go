@@ -1,10 +1,12 @@
 package pkg
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -17,36 +19,65 @@ type HealthResult struct {
 }
 
 // CheckHealth verifies the integrity of a project's dependencies.
-func CheckHealth(dir string, pm PackageManager) HealthResult {
-	result := HealthResult{Dir: dir, PM: pm, Healthy: true}
+// Canceling ctx stops the check command.
+func CheckHealth(ctx context.Context, dir string, pm PackageManager) HealthResult {
+	check, ok := healthChecks[pm]
+	if !ok {
+		return HealthResult{
+			Dir:    dir,
+			PM:     pm,
+			Issues: []string{"Unknown package manager, cannot check health"},
+		}
+	}
+	return check(ctx, dir, pm)
+}
 
-	switch pm {
-	case Npm:
-		result = checkNodeHealth(dir, pm, "npm")
-	case Pnpm:
-		result = checkNodeHealth(dir, pm, "pnpm")
-	case Yarn:
-		result = checkNodeHealth(dir, pm, "yarn")
-	case Bun:
-		result = checkNodeHealth(dir, pm, "bun")
-	case Cargo:
-		result = checkCargoHealth(dir)
-	case Go:
-		result = checkGoHealth(dir)
-	case Pip:
-		result = checkPipHealth(dir)
-	case Deno:
-		result = checkNodeHealth(dir, pm, "deno")
-	default:
-		result.Issues = append(result.Issues, "Unknown package manager, cannot check health")
-		result.Healthy = false
+// healthCheck checks the dependencies of the project in dir, managed by pm.
+type healthCheck func(ctx context.Context, dir string, pm PackageManager) HealthResult
+
+// healthChecks holds the health check of each package manager.
+var healthChecks = map[PackageManager]healthCheck{
+	Npm:      nodeCheck("npm"),
+	Pnpm:     nodeCheck("pnpm"),
+	Yarn:     nodeCheck("yarn"),
+	Bun:      nodeCheck("bun"),
+	Deno:     nodeCheck("deno"),
+	Cargo:    dirCheck(checkCargoHealth),
+	Go:       dirCheck(checkGoHealth),
+	Pip:      checkPythonHealth,
+	Uv:       checkPythonHealth,
+	Poetry:   checkPythonHealth,
+	Pipenv:   checkPythonHealth,
+	Pdm:      checkPythonHealth,
+	Conda:    dirCheck(checkCondaHealth),
+	Maven:    dirCheck(checkMavenHealth),
+	Gradle:   dirCheck(checkGradleHealth),
+	Composer: dirCheck(checkComposerHealth),
+	Bundler:  dirCheck(checkBundlerHealth),
+	Dotnet:   dirCheck(checkDotnetHealth),
+	Mix:      dirCheck(checkMixHealth),
+	Pub:      dirCheck(checkPubHealth),
+	Stack:    checkHaskellHealth,
+	Cabal:    checkHaskellHealth,
+}
+
+// nodeCheck returns the health check of a JavaScript package manager run as binary.
+func nodeCheck(binary string) healthCheck {
+	return func(ctx context.Context, dir string, pm PackageManager) HealthResult {
+		return checkNodeHealth(ctx, dir, pm, binary)
 	}
+}
 
-	return result
+// dirCheck returns check as a healthCheck, for managers that are the only
+// ones to use it.
+func dirCheck(check func(ctx context.Context, dir string) HealthResult) healthCheck {
+	return func(ctx context.Context, dir string, _ PackageManager) HealthResult {
+		return check(ctx, dir)
+	}
 }
 
 // checkNodeHealth checks Node.js project health via `<pm> ls` or install --dry-run.
-func checkNodeHealth(dir string, pm PackageManager, binary string) HealthResult {
+func checkNodeHealth(ctx context.Context, dir string, pm PackageManager, binary string) HealthResult {
 	result := HealthResult{Dir: dir, PM: pm, Healthy: true}
 
 	targetPath := dir + "/node_modules"
@@ -60,18 +91,18 @@ func checkNodeHealth(dir string, pm PackageManager, binary string) HealthResult
 	var cmd *exec.Cmd
 	switch binary {
 	case "npm":
-		cmd = exec.Command("npm", "ls", "--json", "--depth=0")
+		cmd = commandContext(ctx, "npm", "ls", "--json", "--depth=0")
 	case "pnpm":
-		cmd = exec.Command("pnpm", "ls", "--json", "--depth=0")
+		cmd = commandContext(ctx, "pnpm", "ls", "--json", "--depth=0")
 	case "yarn":
-		cmd = exec.Command("yarn", "check", "--verify-tree")
+		cmd = commandContext(ctx, "yarn", "check", "--verify-tree")
 	case "bun":
 		// Bun doesn't have a native ls health check; try a dry install
-		cmd = exec.Command("bun", "install", "--dry-run")
+		cmd = commandContext(ctx, "bun", "install", "--dry-run")
 	case "deno":
-		cmd = exec.Command("deno", "check", ".")
+		cmd = commandContext(ctx, "deno", "check", ".")
 	default:
-		cmd = exec.Command("npm", "ls", "--json", "--depth=0")
+		cmd = commandContext(ctx, "npm", "ls", "--json", "--depth=0")
 	}
 
 	cmd.Dir = dir
@@ -115,7 +146,7 @@ func parseNpmLsOutput(output []byte) []string {
 }
 
 // checkCargoHealth checks Rust project health via `cargo check`.
-func checkCargoHealth(dir string) HealthResult {
+func checkCargoHealth(ctx context.Context, dir string) HealthResult {
 	result := HealthResult{Dir: dir, PM: Cargo, Healthy: true}
 
 	targetPath := dir + "/target"
@@ -125,7 +156,7 @@ func checkCargoHealth(dir string) HealthResult {
 		return result
 	}
 
-	cmd := exec.Command("cargo", "check")
+	cmd := commandContext(ctx, "cargo", "check")
 	cmd.Dir = dir
 	output, err := cmd.CombinedOutput()
 
@@ -149,10 +180,27 @@ func checkCargoHealth(dir string) HealthResult {
 }
 
 // checkGoHealth checks Go project health via `go mod verify`.
-func checkGoHealth(dir string) HealthResult {
+func checkGoHealth(ctx context.Context, dir string) HealthResult {
 	result := HealthResult{Dir: dir, PM: Go, Healthy: true}
 
-	cmd := exec.Command("go", "mod", "verify")
+	cmd := commandContext(ctx, "go", "mod", "verify")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		result.Healthy = false
+		result.Issues = outputIssues(output, "go mod verify failed")
+	}
+
+	return result
+}
+
+// checkMavenHealth checks Maven project health by resolving its dependencies
+// offline, which fails if any is missing from the local repository.
+func checkMavenHealth(ctx context.Context, dir string) HealthResult {
+	result := HealthResult{Dir: dir, PM: Maven, Healthy: true}
+
+	cmd := commandContext(ctx, wrapperOr(dir, "mvnw", "mvn"), "--offline", "--batch-mode", "dependency:resolve")
 	cmd.Dir = dir
 	output, err := cmd.CombinedOutput()
 
@@ -160,54 +208,202 @@ func checkGoHealth(dir string) HealthResult {
 		result.Healthy = false
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
-			trimmed := strings.TrimSpace(line)
-			if trimmed != "" {
-				result.Issues = append(result.Issues, trimmed)
+			if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "[ERROR]") {
+				result.Issues = append(result.Issues, strings.TrimSpace(strings.TrimPrefix(trimmed, "[ERROR]")))
 				if len(result.Issues) >= 5 {
 					break
 				}
 			}
 		}
 		if len(result.Issues) == 0 {
-			result.Issues = append(result.Issues, "go mod verify failed")
+			result.Issues = append(result.Issues, "mvn dependency:resolve failed")
 		}
 	}
 
 	return result
 }
 
-// checkPipHealth checks Python project health by verifying installed packages.
-func checkPipHealth(dir string) HealthResult {
-	result := HealthResult{Dir: dir, PM: Pip, Healthy: true}
+// checkGradleHealth checks Gradle project health by resolving its dependencies
+// offline. Gradle marks unresolvable dependencies FAILED in the report but
+// still exits successfully, so the output is checked too.
+func checkGradleHealth(ctx context.Context, dir string) HealthResult {
+	result := HealthResult{Dir: dir, PM: Gradle, Healthy: true}
 
-	venvPath := dir + "/.venv"
-	if !DirExists(venvPath) {
+	cmd := commandContext(ctx, wrapperOr(dir, "gradlew", "gradle"), "--offline", "--quiet", "dependencies")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		// The same dependency is listed once per configuration.
+		if trimmed := strings.TrimSpace(line); strings.HasSuffix(trimmed, "FAILED") {
+			issue := strings.TrimLeft(trimmed, "+\\|- ")
+			if slices.Contains(result.Issues, issue) {
+				continue
+			}
+			result.Issues = append(result.Issues, issue)
+			if len(result.Issues) >= 5 {
+				break
+			}
+		}
+	}
+	if err != nil && len(result.Issues) == 0 {
+		result.Issues = append(result.Issues, "gradle dependencies failed")
+	}
+	result.Healthy = len(result.Issues) == 0
+
+	return result
+}
+
+// checkComposerHealth checks PHP project health via `composer validate` and
+// `composer check-platform-reqs`.
+func checkComposerHealth(ctx context.Context, dir string) HealthResult {
+	result := HealthResult{Dir: dir, PM: Composer, Healthy: true}
+
+	if !DirExists(filepath.Join(dir, "vendor")) {
 		result.Healthy = false
-		result.Issues = append(result.Issues, ".venv not found")
+		result.Issues = append(result.Issues, "vendor/ not found")
 		return result
 	}
 
-	// Try pip check inside the venv
-	pipBin := filepath.Join(filepath.Clean(venvPath), "bin", "pip")
-	cmd := exec.Command(pipBin, "check") //nolint:gosec // path is constructed from known project directory
+	checks := [][]string{
+		{"validate", "--no-check-publish", "--no-interaction"},
+		{"check-platform-reqs", "--no-interaction"},
+	}
+	for _, args := range checks {
+		cmd := commandContext(ctx, "composer", args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+
+		if err != nil {
+			result.Healthy = false
+			result.Issues = append(result.Issues, outputIssues(output, "composer "+args[0]+" failed")...)
+		}
+	}
+
+	return result
+}
+
+// checkBundlerHealth checks Ruby project health via `bundle check`, which
+// fails if any gem in Gemfile.lock is not installed.
+func checkBundlerHealth(ctx context.Context, dir string) HealthResult {
+	result := HealthResult{Dir: dir, PM: Bundler, Healthy: true}
+
+	cmd := commandContext(ctx, "bundle", "check")
 	cmd.Dir = dir
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
 		result.Healthy = false
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			trimmed := strings.TrimSpace(line)
-			if trimmed != "" {
-				result.Issues = append(result.Issues, trimmed)
-				if len(result.Issues) >= 5 {
-					break
-				}
+		result.Issues = outputIssues(output, "bundle check failed")
+	}
+
+	return result
+}
+
+// outputIssues returns the first non-empty lines of a failed check's output,
+// or fallback if there are none.
+func outputIssues(output []byte, fallback string) []string {
+	var issues []string
+	for _, line := range strings.Split(string(output), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" {
+			issues = append(issues, trimmed)
+			if len(issues) >= 5 {
+				break
 			}
 		}
-		if len(result.Issues) == 0 {
-			result.Issues = append(result.Issues, "pip check failed")
-		}
+	}
+	if len(issues) == 0 {
+		issues = append(issues, fallback)
+	}
+	return issues
+}
+
+// checkDotnetHealth checks .NET project health via `dotnet list package`,
+// which fails when the project has not been restored.
+func checkDotnetHealth(ctx context.Context, dir string) HealthResult {
+	result := HealthResult{Dir: dir, PM: Dotnet, Healthy: true}
+
+	cmd := commandContext(ctx, "dotnet", "list", "package")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		result.Healthy = false
+		result.Issues = outputIssues(output, "dotnet list package failed")
+	}
+
+	return result
+}
+
+// checkMixHealth checks Elixir project health via `mix deps.loadpaths`,
+// which fails if a dependency is missing or out of date with mix.lock.
+func checkMixHealth(ctx context.Context, dir string) HealthResult {
+	result := HealthResult{Dir: dir, PM: Mix, Healthy: true}
+
+	if !DirExists(filepath.Join(dir, "deps")) {
+		result.Healthy = false
+		result.Issues = append(result.Issues, "deps/ not found")
+		return result
+	}
+
+	cmd := commandContext(ctx, "mix", "deps.loadpaths", "--no-compile")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		result.Healthy = false
+		result.Issues = outputIssues(output, "mix deps.loadpaths failed")
+	}
+
+	return result
+}
+
+// checkPubHealth checks Dart and Flutter project health via `pub deps`,
+// which fails when dependencies were never fetched or pubspec.yaml changed
+// since.
+func checkPubHealth(ctx context.Context, dir string) HealthResult {
+	result := HealthResult{Dir: dir, PM: Pub, Healthy: true}
+
+	if !DirExists(filepath.Join(dir, ".dart_tool")) {
+		result.Healthy = false
+		result.Issues = append(result.Issues, ".dart_tool/ not found")
+		return result
+	}
+
+	cmd := commandContext(ctx, dartTool(dir), "pub", "deps")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		result.Healthy = false
+		result.Issues = outputIssues(output, "pub deps failed")
+	}
+
+	return result
+}
+
+// checkHaskellHealth checks Stack and Cabal project health with a dry run of
+// building the dependencies, which lists any that are not built yet.
+func checkHaskellHealth(ctx context.Context, dir string, pm PackageManager) HealthResult {
+	result := HealthResult{Dir: dir, PM: pm, Healthy: true}
+
+	binary, pending := "cabal", "would be built"
+	if pm == Stack {
+		binary, pending = "stack", "Would build"
+	}
+	cmd := commandContext(ctx, binary, "build", "--only-dependencies", "--dry-run")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	switch {
+	case err != nil:
+		result.Healthy = false
+		result.Issues = outputIssues(output, binary+" build --dry-run failed")
+	case strings.Contains(string(output), pending):
+		result.Healthy = false
+		result.Issues = append(result.Issues, "dependencies are not built")
 	}
 
 	return result
@@ -5,6 +5,8 @@ package pkg
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 // PackageManager represents the type of package manager detected in a project.
@@ -12,45 +14,167 @@ type PackageManager string
 
 // Supported package managers.
 const (
-	Npm     PackageManager = "npm"
-	Pnpm    PackageManager = "pnpm"
-	Yarn    PackageManager = "yarn"
-	Bun     PackageManager = "bun"
-	Deno    PackageManager = "deno"
-	Cargo   PackageManager = "cargo"
-	Go      PackageManager = "go"
-	Pip     PackageManager = "pip"
-	Unknown PackageManager = "unknown"
+	Npm      PackageManager = "npm"
+	Pnpm     PackageManager = "pnpm"
+	Yarn     PackageManager = "yarn"
+	Bun      PackageManager = "bun"
+	Deno     PackageManager = "deno"
+	Cargo    PackageManager = "cargo"
+	Go       PackageManager = "go"
+	Pip      PackageManager = "pip"
+	Maven    PackageManager = "maven"
+	Gradle   PackageManager = "gradle"
+	Composer PackageManager = "composer"
+	Bundler  PackageManager = "bundler"
+	Uv       PackageManager = "uv"
+	Poetry   PackageManager = "poetry"
+	Pipenv   PackageManager = "pipenv"
+	Pdm      PackageManager = "pdm"
+	Conda    PackageManager = "conda"
+	Dotnet   PackageManager = "dotnet"
+	Mix      PackageManager = "mix"
+	Pub      PackageManager = "pub"
+	Stack    PackageManager = "stack"
+	Cabal    PackageManager = "cabal"
+	Unknown  PackageManager = "unknown"
 )
 
-// DetectManager identifies the package manager used in dir by checking for lock files.
+// managerFiles lists, in priority order, the lock files and manifests that
+// identify each package manager. Names may be glob patterns.
+var managerFiles = []struct {
+	pm    PackageManager
+	files []string
+}{
+	{Bun, []string{"bun.lockb", "bun.lock"}},
+	{Pnpm, []string{"pnpm-lock.yaml"}},
+	{Yarn, []string{"yarn.lock"}},
+	{Npm, []string{"package-lock.json"}},
+	{Deno, []string{"deno.json", "deno.jsonc"}},
+	{Cargo, []string{"Cargo.toml"}},
+	{Go, []string{"go.mod"}},
+	{Maven, []string{"pom.xml"}},
+	{Gradle, []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}},
+	{Composer, []string{"composer.lock", "composer.json"}},
+	{Bundler, []string{"Gemfile.lock", "Gemfile"}},
+	{Dotnet, []string{"packages.lock.json", "*.sln", "*.csproj", "*.fsproj", "*.vbproj"}},
+	{Mix, []string{"mix.lock", "mix.exs"}},
+	{Pub, []string{"pubspec.lock", "pubspec.yaml"}},
+	// Stack projects also have the .cabal files hpack generates.
+	{Stack, []string{"stack.yaml"}},
+	{Cabal, []string{"cabal.project", "*.cabal"}},
+	// Python projects often keep a requirements.txt or pyproject.toml next
+	// to their real lockfile, so pip comes last.
+	{Uv, []string{"uv.lock"}},
+	{Poetry, []string{"poetry.lock"}},
+	{Pdm, []string{"pdm.lock"}},
+	{Pipenv, []string{"Pipfile.lock", "Pipfile"}},
+	{Conda, []string{"environment.yml", "environment.yaml"}},
+	{Pip, []string{"requirements.txt", "pyproject.toml"}},
+}
+
+// DetectManager identifies the package manager used in dir by checking for
+// lock files and manifests. When several are present, the first manager in
+// priority order wins.
 func DetectManager(dir string) PackageManager {
-	managers := map[PackageManager][]string{
-		Bun:   {"bun.lockb", "bun.lock"},
-		Pnpm:  {"pnpm-lock.yaml"},
-		Yarn:  {"yarn.lock"},
-		Npm:   {"package-lock.json"},
-		Deno:  {"deno.json", "deno.jsonc"},
-		Cargo: {"Cargo.toml"},
-		Go:    {"go.mod"},
-		Pip:   {"requirements.txt", "pyproject.toml"},
-	}
-
-	for mgr, files := range managers {
+	if managers := DetectManagers(dir); len(managers) > 0 {
+		return managers[0]
+	}
+	return Unknown
+}
+
+// DetectManagers returns every package manager with a lock file or manifest
+// in dir, in priority order, e.g. npm and Composer for a Laravel app.
+func DetectManagers(dir string) []PackageManager {
+	var managers []PackageManager
+	files := dirFiles{dir: dir}
+	for _, m := range managerFiles {
+		for _, f := range m.files {
+			if files.has(f) {
+				managers = append(managers, m.pm)
+				break
+			}
+		}
+	}
+	return managers
+}
+
+// IsManifest reports whether a file called name is a lock file or manifest
+// of a package manager, or one of the manifests the target table looks for.
+func IsManifest(name string) bool {
+	if manifestNames[name] {
+		return true
+	}
+	for _, pattern := range manifestPatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestNames and manifestPatterns are the files IsManifest looks for,
+// split into literal names and the few glob patterns, such as *.csproj.
+var manifestNames, manifestPatterns = manifestSets()
+
+func manifestSets() (map[string]bool, []string) {
+	names := make(map[string]bool)
+	var patterns []string
+	add := func(files []string) {
 		for _, f := range files {
-			if fileExists(filepath.Join(dir, f)) {
-				return mgr
+			if !strings.ContainsAny(f, "*?[") {
+				names[f] = true
+			} else if !slices.Contains(patterns, f) {
+				patterns = append(patterns, f)
 			}
 		}
 	}
+	for _, m := range managerFiles {
+		add(m.files)
+	}
+	for _, t := range Targets {
+		add(t.Manifests)
+	}
+	return names, patterns
+}
 
-	return Unknown
+// HasFile reports whether dir contains a file called name, or, if name is a
+// glob pattern such as *.csproj, a file matching it.
+func HasFile(dir, name string) bool {
+	files := dirFiles{dir: dir}
+	return files.has(name)
 }
 
-func fileExists(filename string) bool {
-	info, err := os.Stat(filename)
-	if os.IsNotExist(err) {
-		return false
+// dirFiles looks up files in one directory. Literal names are checked with a
+// stat; for glob patterns the directory is read once, and its entries'
+// names are matched, so that dir is never itself taken for a pattern.
+type dirFiles struct {
+	dir     string
+	entries []os.DirEntry
+	read    bool
+}
+
+// has reports whether the directory contains a file called name, or one
+// matching it if name is a glob pattern.
+func (d *dirFiles) has(name string) bool {
+	if !strings.ContainsAny(name, "*?[") {
+		return FileExists(filepath.Join(d.dir, name))
+	}
+	if !d.read {
+		d.entries, _ = os.ReadDir(d.dir)
+		d.read = true
+	}
+	for _, entry := range d.entries {
+		if ok, _ := filepath.Match(name, entry.Name()); !ok {
+			continue
+		}
+		// A symlink counts if it leads to a file.
+		if entry.Type()&os.ModeSymlink != 0 {
+			if FileExists(filepath.Join(d.dir, entry.Name())) {
+				return true
+			}
+		} else if !entry.IsDir() {
+			return true
+		}
 	}
-	return !info.IsDir()
+	return false
 }
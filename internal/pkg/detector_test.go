@@ -3,6 +3,7 @@ package pkg
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -27,6 +28,26 @@ func TestDetectManager(t *testing.T) {
 		{"Go project", "go.mod", Go},
 		{"Python project pip", "requirements.txt", Pip},
 		{"Python pyproject", "pyproject.toml", Pip},
+		{"Maven project", "pom.xml", Maven},
+		{"Gradle project", "build.gradle", Gradle},
+		{"Gradle Kotlin DSL project", "build.gradle.kts", Gradle},
+		{"Gradle multi-project root", "settings.gradle.kts", Gradle},
+		{"PHP Composer project", "composer.json", Composer},
+		{"PHP Composer lockfile", "composer.lock", Composer},
+		{"Ruby Bundler project", "Gemfile", Bundler},
+		{"Ruby Bundler lockfile", "Gemfile.lock", Bundler},
+		{"Python uv project", "uv.lock", Uv},
+		{"Python Poetry project", "poetry.lock", Poetry},
+		{"Python Pipenv project", "Pipfile.lock", Pipenv},
+		{"Python PDM project", "pdm.lock", Pdm},
+		{"Python conda project", "environment.yml", Conda},
+		{".NET project", "Api.csproj", Dotnet},
+		{".NET solution", "Shop.sln", Dotnet},
+		{"Elixir project", "mix.exs", Mix},
+		{"Dart project", "pubspec.yaml", Pub},
+		{"Haskell Stack project", "stack.yaml", Stack},
+		{"Haskell Cabal project", "cabal.project", Cabal},
+		{"Haskell Cabal package", "parser.cabal", Cabal},
 		{"Unknown project", "random.txt", Unknown},
 	}
 
@@ -54,3 +75,82 @@ func TestDetectManager(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectManagerPriority(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"requirements.txt", "build.gradle", "pom.xml", "package-lock.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Detection must not depend on map order: npm's lockfile outranks every
+	// other manifest here, on every call.
+	for i := 0; i < 20; i++ {
+		if pm := DetectManager(dir); pm != Npm {
+			t.Fatalf("DetectManager() = %v, want %v", pm, Npm)
+		}
+	}
+
+	want := []PackageManager{Npm, Maven, Gradle, Pip}
+	if got := DetectManagers(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectManagers() = %v, want %v", got, want)
+	}
+}
+
+func TestIsManifest(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"package.json", true},
+		{"Cargo.toml", true},
+		{"pyproject.toml", true},
+		{"App.csproj", true},
+		{"Game.uproject", true},
+		{"README.md", false},
+		{"csproj", false},
+		{"ProjectVersion.txt", false},
+	}
+	for _, tt := range tests {
+		if got := IsManifest(tt.name); got != tt.want {
+			t.Errorf("IsManifest(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHasFileInBracketedDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "[wip] shop*")
+	if err := os.MkdirAll(filepath.Join(dir, "Assets.csproj"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Api.csproj"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The directory's own [ and * are no pattern, and only files match.
+	if !HasFile(dir, "*.csproj") {
+		t.Errorf("HasFile(%q, *.csproj) = false, want true", dir)
+	}
+	if HasFile(dir, "Assets.*") {
+		t.Errorf("HasFile(%q, Assets.*) = true, want false for a directory", dir)
+	}
+	if pm := DetectManager(dir); pm != Dotnet {
+		t.Errorf("DetectManager() = %v, want %v", pm, Dotnet)
+	}
+}
+
+func TestHasFileStatError(t *testing.T) {
+	// Stat through a regular file fails with ENOTDIR, not "not exist", as
+	// it fails with EACCES inside another user's directory.
+	file := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if HasFile(file, "package.json") {
+		t.Errorf("HasFile(%q, package.json) = true, want false", file)
+	}
+	if pm := DetectManager(file); pm != Unknown {
+		t.Errorf("DetectManager(%q) = %v, want %v", file, pm, Unknown)
+	}
+}
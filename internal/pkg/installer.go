@@ -1,46 +1,94 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 )
 
 // InstallDependencies runs the appropriate install command based on the package manager.
-func InstallDependencies(dir string, pm PackageManager, silent bool) error {
-	var cmd *exec.Cmd
-
-	switch pm {
-	case Bun:
-		cmd = exec.Command("bun", "install")
-	case Pnpm:
-		cmd = exec.Command("pnpm", "install")
-	case Yarn:
-		cmd = exec.Command("yarn", "install")
-	case Npm:
-		cmd = exec.Command("npm", "install")
-	case Deno:
-		cmd = exec.Command("deno", "install") // Deno 2.x supports this
-	case Cargo:
-		cmd = exec.Command("cargo", "build")
-	case Go:
-		cmd = exec.Command("go", "mod", "tidy")
-	case Pip:
-		cmd = exec.Command("pip", "install", "-r", "requirements.txt") // Usually requires careful venv handling but good enough for MVP
-	default:
+// The command's output goes to out, or is discarded if out is nil.
+// Canceling ctx interrupts the install command.
+func InstallDependencies(ctx context.Context, dir string, pm PackageManager, out io.Writer) error {
+	if _, ok := pythonInstallers[pm]; ok {
+		return installPython(ctx, dir, pm, out)
+	}
+	command, ok := installCommands[pm]
+	if !ok {
 		return fmt.Errorf("unknown package manager, cannot run install")
 	}
 
+	args := command(dir)
+	cmd := commandContext(ctx, args[0], args[1:]...)
 	cmd.Dir = dir
 
-	if !silent {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		fmt.Printf("📦 Running %s install...\n", pm)
-		return cmd.Run()
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// installCommands gives, for each package manager but the Python ones, the
+// command that installs the dependencies of the project in dir.
+var installCommands = map[PackageManager]func(dir string) []string{
+	Bun:  command("bun", "install"),
+	Pnpm: command("pnpm", "install"),
+	Yarn: command("yarn", "install"),
+	Npm:  command("npm", "install"),
+	// Deno 2.x supports this
+	Deno:  command("deno", "install"),
+	Cargo: command("cargo", "build"),
+	Go:    command("go", "mod", "tidy"),
+	Maven: func(dir string) []string {
+		return []string{wrapperOr(dir, "mvnw", "mvn"), "dependency:go-offline"}
+	},
+	Gradle: func(dir string) []string {
+		return []string{wrapperOr(dir, "gradlew", "gradle"), "dependencies"}
+	},
+	Composer: command("composer", "install"),
+	Bundler:  command("bundle", "install"),
+	Dotnet:   command("dotnet", "restore"),
+	Mix:      command("mix", "deps.get"),
+	Pub: func(dir string) []string {
+		return []string{dartTool(dir), "pub", "get"}
+	},
+	Stack: command("stack", "build", "--only-dependencies"),
+	Cabal: command("cabal", "build", "--only-dependencies"),
+}
+
+// command returns an install command that is the same in every project.
+func command(args ...string) func(dir string) []string {
+	return func(string) []string { return args }
+}
+
+// wrapperOr returns the command running a JVM build tool in dir: the
+// project's wrapper script (mvnw, gradlew) when it has one, since it pins the
+// tool version the build expects, or else binary from PATH.
+func wrapperOr(dir, wrapper, binary string) string {
+	if runtime.GOOS == "windows" {
+		if wrapper == "gradlew" {
+			wrapper += ".bat"
+		} else {
+			wrapper += ".cmd"
+		}
+	}
+	if !FileExists(filepath.Join(dir, wrapper)) {
+		return binary
 	}
+	// A relative command path is resolved against the command's Dir, which
+	// is set to dir.
+	return "." + string(filepath.Separator) + wrapper
+}
 
-	// Capture outputs to suppress default stdout mess
-	_, err := cmd.CombinedOutput()
-	return err
+// dartTool returns the command running pub for the Dart project in dir:
+// flutter for Flutter apps, whose pubspec depends on the Flutter SDK, or dart.
+func dartTool(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "pubspec.yaml")) //nolint:gosec // path is constructed from known project directory
+	if err == nil && strings.Contains(string(data), "sdk: flutter") {
+		return "flutter"
+	}
+	return "dart"
 }
@@ -0,0 +1,316 @@
+package pkg
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// installPython installs a Python project's dependencies into its in-project
+// virtual environment, creating a .venv with the interpreter named by
+// .python-version if needed. Conda environments live outside the project and
+// are updated in place.
+func installPython(ctx context.Context, dir string, pm PackageManager, out io.Writer) error {
+	run := func(env []string, name string, args ...string) error {
+		cmd := commandContext(ctx, name, args...)
+		cmd.Dir = dir
+		if env != nil {
+			cmd.Env = append(os.Environ(), env...)
+		}
+		cmd.Stdout = out
+		cmd.Stderr = out
+		return cmd.Run()
+	}
+
+	install, ok := pythonInstallers[pm]
+	if !ok {
+		install = installPip
+	}
+	return install(run, dir, pythonVersion(dir))
+}
+
+// runFunc runs a command in the project directory, with env added to the
+// environment.
+type runFunc func(env []string, name string, args ...string) error
+
+// pythonInstallers install the dependencies of a project in dir with each
+// Python package manager, given the Python version it pins, if any.
+var pythonInstallers = map[PackageManager]func(run runFunc, dir, version string) error{
+	Pip:    installPip,
+	Uv:     installUv,
+	Poetry: installPoetry,
+	Pipenv: installPipenv,
+	Pdm:    installPdm,
+	Conda:  installConda,
+}
+
+// installUv runs uv sync, which creates .venv itself and already honors
+// .python-version.
+func installUv(run runFunc, _, _ string) error {
+	return run(nil, "uv", "sync")
+}
+
+// installPoetry runs poetry install into an in-project .venv, first
+// pointing Poetry at the pinned Python version.
+func installPoetry(run runFunc, _, version string) error {
+	env := []string{"POETRY_VIRTUALENVS_IN_PROJECT=true"}
+	if version != "" {
+		if err := run(env, "poetry", "env", "use", version); err != nil {
+			return err
+		}
+	}
+	return run(env, "poetry", "install")
+}
+
+// installPipenv runs pipenv install --deploy into an in-project .venv.
+func installPipenv(run runFunc, _, version string) error {
+	args := []string{"install", "--deploy"}
+	if version != "" {
+		args = append(args, "--python", version)
+	}
+	return run([]string{"PIPENV_VENV_IN_PROJECT=1"}, "pipenv", args...)
+}
+
+// installPdm runs pdm install, first choosing the pinned Python version for
+// a new .venv.
+func installPdm(run runFunc, dir, version string) error {
+	if version != "" && !DirExists(filepath.Join(dir, ".venv")) {
+		if err := run(nil, "pdm", "use", "--first", version); err != nil {
+			return err
+		}
+	}
+	return run(nil, "pdm", "install")
+}
+
+// installConda updates the conda environment named by the project's
+// environment file.
+func installConda(run runFunc, dir, _ string) error {
+	return run(nil, "conda", "env", "update", "--file", condaFile(dir), "--prune")
+}
+
+// installPip installs with pip into the project's venv, creating a .venv if
+// it has none.
+func installPip(run runFunc, dir, version string) error {
+	venv := LocalVenv(dir)
+	if venv == "" {
+		venv = ".venv"
+		if err := run(nil, pythonCommand(version), "-m", "venv", venv); err != nil {
+			return err
+		}
+	}
+	args := []string{"-m", "pip", "install", "-e", "."}
+	if FileExists(filepath.Join(dir, "requirements.txt")) {
+		args = []string{"-m", "pip", "install", "-r", "requirements.txt"}
+	}
+	return run(nil, venvPython(venv), args...)
+}
+
+// checkPythonHealth checks a Python project's virtual environment: that it
+// exists, that its own interpreter still runs (a venv breaks when the Python
+// it was created from is upgraded or removed), and that the installed
+// packages' requirements are met.
+func checkPythonHealth(ctx context.Context, dir string, pm PackageManager) HealthResult {
+	result := HealthResult{Dir: dir, PM: pm, Healthy: true}
+
+	venv := findVenv(ctx, dir, pm)
+	if venv == "" {
+		result.Healthy = false
+		result.Issues = append(result.Issues, "virtualenv not found")
+		return result
+	}
+	python := venvPython(venv)
+
+	cmd := commandContext(ctx, python, "--version")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		result.Healthy = false
+		result.Issues = append(result.Issues, "virtualenv interpreter does not run (was its Python upgraded or removed?)")
+		return result
+	}
+
+	// uv and PDM create venvs without pip, so pip check is run by uv or,
+	// failing that, skipped.
+	switch {
+	case pm == Uv:
+		cmd = commandContext(ctx, "uv", "pip", "check", "--python", python)
+	case hasPip(dir, venv):
+		cmd = commandContext(ctx, python, "-m", "pip", "check")
+	default:
+		return result
+	}
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		result.Healthy = false
+		result.Issues = outputIssues(output, "pip check failed")
+	}
+
+	return result
+}
+
+// checkCondaHealth checks a conda project via `conda compare`, which reports
+// packages of environment.yml missing from, or at other versions in, the
+// environment it names.
+func checkCondaHealth(ctx context.Context, dir string) HealthResult {
+	result := HealthResult{Dir: dir, PM: Conda, Healthy: true}
+
+	file := condaFile(dir)
+	name := condaEnvName(filepath.Join(dir, file))
+	if name == "" {
+		result.Healthy = false
+		result.Issues = append(result.Issues, file+" does not name an environment")
+		return result
+	}
+
+	cmd := commandContext(ctx, "conda", "compare", "--name", name, file)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		result.Healthy = false
+		result.Issues = outputIssues(output, "conda compare failed")
+	}
+
+	return result
+}
+
+// findVenv returns the project's virtual environment, relative to dir
+// unless it is absolute, or "" if it has none. Poetry and Pipenv keep venvs
+// outside the project unless configured otherwise, so they are asked.
+func findVenv(ctx context.Context, dir string, pm PackageManager) string {
+	if venv := LocalVenv(dir); venv != "" {
+		return venv
+	}
+
+	var cmd *exec.Cmd
+	switch pm {
+	case Poetry:
+		cmd = commandContext(ctx, "poetry", "env", "info", "--path")
+	case Pipenv:
+		cmd = commandContext(ctx, "pipenv", "--venv")
+	default:
+		return ""
+	}
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	venv := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(venv) || !DirExists(venv) {
+		return ""
+	}
+	return venv
+}
+
+// LocalVenv returns the name of the in-project virtual environment of the
+// project in dir, or "" if it has none: .venv, or else the first folder at the
+// top of dir that the scanner would take for a venv, such as venv or env
+// with a pyvenv.cfg.
+func LocalVenv(dir string) string {
+	if DirExists(filepath.Join(dir, ".venv")) {
+		return ".venv"
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	venv := TargetsNamed(".venv")[0]
+	for _, entry := range entries {
+		if entry.IsDir() && venv.Signed(filepath.Join(dir, entry.Name())) {
+			return entry.Name()
+		}
+	}
+	return ""
+}
+
+// venvPython returns the interpreter of the virtual environment at venv.
+func venvPython(venv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venv, "Scripts", "python.exe")
+	}
+	return filepath.Join(venv, "bin", "python")
+}
+
+// hasPip reports whether the virtual environment at venv, relative to dir
+// unless absolute, has pip installed.
+func hasPip(dir, venv string) bool {
+	if !filepath.IsAbs(venv) {
+		venv = filepath.Join(dir, venv)
+	}
+	pip := filepath.Join(venv, "bin", "pip")
+	if runtime.GOOS == "windows" {
+		pip = filepath.Join(venv, "Scripts", "pip.exe")
+	}
+	return FileExists(pip)
+}
+
+// pythonVersion returns the major.minor Python version pinned by the
+// project's .python-version, e.g. "3.12" for "3.12.4", or "" if there is
+// none or it names something else, such as a pyenv virtualenv.
+func pythonVersion(dir string) string {
+	f, err := os.Open(filepath.Join(dir, ".python-version")) //nolint:gosec // path is constructed from known project directory
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ".")
+		if len(parts) < 2 || !isDigits(parts[0]) || !isDigits(parts[1]) {
+			return ""
+		}
+		return parts[0] + "." + parts[1]
+	}
+	return ""
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// pythonCommand returns the interpreter to create a venv with: python3.12
+// for version "3.12", or the default Python 3 if version is "".
+func pythonCommand(version string) string {
+	if runtime.GOOS == "windows" {
+		// The python.org installer puts only python.exe on PATH.
+		return "python"
+	}
+	if version == "" {
+		return "python3"
+	}
+	return "python" + version
+}
+
+// condaFile returns the name of the project's conda environment file.
+func condaFile(dir string) string {
+	if FileExists(filepath.Join(dir, "environment.yaml")) && !FileExists(filepath.Join(dir, "environment.yml")) {
+		return "environment.yaml"
+	}
+	return "environment.yml"
+}
+
+// condaEnvName returns the top-level name: of a conda environment file.
+func condaEnvName(file string) string {
+	data, err := os.ReadFile(file) //nolint:gosec // path is constructed from known project directory
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, ok := strings.CutPrefix(line, "name:"); ok {
+			return strings.Trim(strings.TrimSpace(name), `"'`)
+		}
+	}
+	return ""
+}
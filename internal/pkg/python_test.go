@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPythonVersion(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"3.12.4\n", "3.12"},
+		{"# pinned\n3.11\n", "3.11"},
+		{"pypy3.10\n", ""},
+		{"my-virtualenv\n", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, ".python-version"), []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if got := pythonVersion(dir); got != tt.want {
+			t.Errorf("pythonVersion(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+
+	if got := pythonVersion(t.TempDir()); got != "" {
+		t.Errorf("pythonVersion() without .python-version = %q, want \"\"", got)
+	}
+}
+
+func TestCondaEnvName(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "environment.yml")
+	content := "name: \"ml-env\"\nchannels:\n  - conda-forge\ndependencies:\n  - name: not-this\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := condaEnvName(file); got != "ml-env" {
+		t.Errorf("condaEnvName() = %q, want %q", got, "ml-env")
+	}
+}
+
+func TestCheckPythonHealthRunsVenvInterpreter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell script as the interpreter")
+	}
+
+	tests := []struct {
+		name    string
+		venv    string // the venv folder, with a pyvenv.cfg unless it is .venv
+		python  string // script standing in for <venv>/bin/python; "" for none
+		healthy bool
+	}{
+		{"working interpreter", ".venv", "#!/bin/sh\nexit 0\n", true},
+		{"broken interpreter", ".venv", "#!/bin/sh\nexit 1\n", false},
+		{"venv by pyvenv.cfg", "env", "#!/bin/sh\nexit 0\n", true},
+		{"no venv", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.python != "" {
+				bin := filepath.Join(dir, tt.venv, "bin")
+				if err := os.MkdirAll(bin, 0o750); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(bin, "python"), []byte(tt.python), 0o700); err != nil { //nolint:gosec // test script must be executable
+					t.Fatal(err)
+				}
+				if tt.venv != ".venv" {
+					if err := os.WriteFile(filepath.Join(dir, tt.venv, "pyvenv.cfg"), []byte("home = /usr/bin\n"), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			// The venv has no pip, as with PDM, so only the interpreter is checked.
+			result := checkPythonHealth(context.Background(), dir, Pdm)
+			if result.Healthy != tt.healthy {
+				t.Errorf("Healthy = %v, want %v (issues: %v)", result.Healthy, tt.healthy, result.Issues)
+			}
+		})
+	}
+}
+
+func TestLocalVenv(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"src", "venv"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := LocalVenv(dir); got != "" {
+		t.Errorf("LocalVenv() without pyvenv.cfg = %q, want \"\"", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "venv", "pyvenv.cfg"), []byte("home = /usr/bin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := LocalVenv(dir); got != "venv" {
+		t.Errorf("LocalVenv() = %q, want %q", got, "venv")
+	}
+}
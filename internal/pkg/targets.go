@@ -0,0 +1,240 @@
+package pkg
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// TargetKind says what a target folder holds.
+type TargetKind string
+
+// Kinds of target folders.
+const (
+	// KindDependencies folders hold installed dependencies, or other state
+	// that is judged by how stale its project is.
+	KindDependencies TargetKind = "dependencies"
+	// KindCache folders hold build output or caches that the next build
+	// re-generates.
+	KindCache TargetKind = "cache"
+)
+
+// Target is one entry of the target table: a folder name pumu deletes, the
+// ecosystem it belongs to and what backs it up as that ecosystem's folder. A
+// name may have several entries, one per ecosystem that uses it; the first
+// that matches a folder wins.
+type Target struct {
+	// Name is the folder's name, or a glob pattern such as "*.egg-info".
+	Name      string
+	Ecosystem string
+	Kind      TargetKind
+	// Manifests are the files, or globs, of which one must exist in the
+	// folder's project for the entry to match. None means no evidence is
+	// needed.
+	Manifests []string
+	// Under is the name the folder's parent must have, such as vendor for
+	// Bundler's vendor/bundle. The folder's project is then the directory
+	// above its parent.
+	Under string
+	// Anchored names are too common to be flagged even as unverified: a
+	// folder that matches none of the name's entries is an ordinary directory
+	// (Go's vendor, a Rails app's vendor/assets) and the walk goes on below it.
+	Anchored bool
+	// Signatures are files at the top of the folder, any one of which
+	// identifies it whatever it is called, such as the pyvenv.cfg of every
+	// Python virtual environment. A folder with one needs no manifest.
+	Signatures []string
+	// SrcLayout entries also find their manifests one level up when the
+	// folder's parent is src, as with setuptools' src layout, where
+	// pyproject.toml is at the project root and src/pkg.egg-info below it.
+	SrcLayout bool
+	// Aggregated targets are scattered through a project's source tree, one
+	// per package, such as Python's __pycache__, and are reported as one
+	// aggregate per project rather than one by one.
+	Aggregated bool
+}
+
+// Manifests shared by several targets.
+var (
+	nodeManifests   = []string{"package.json"}
+	gradleScripts   = []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}
+	pythonProjects  = []string{"pyproject.toml", "setup.py"}
+	dotnetProjects  = []string{"*.csproj", "*.fsproj", "*.vbproj"}
+	composerFiles   = []string{"composer.json", "composer.lock"}
+	mixProjects     = []string{"mix.exs"}
+	pubspecManifest = []string{"pubspec.yaml"}
+	unityProjects   = []string{filepath.Join("ProjectSettings", "ProjectVersion.txt")}
+	unrealProjects  = []string{"*.uproject"}
+)
+
+// Targets is the table of known heavy dependency and build folders. Adding a
+// framework's cache is a matter of adding its entry here.
+var Targets = []Target{
+	// JavaScript
+	// npm, pnpm and yarn each leave a state file in the node_modules they install.
+	{Name: "node_modules", Ecosystem: "node", Kind: KindDependencies, Signatures: []string{".package-lock.json", ".modules.yaml", ".yarn-state.yml"}},
+	{Name: ".next", Ecosystem: "node", Kind: KindCache},
+	{Name: ".svelte-kit", Ecosystem: "node", Kind: KindCache},
+	{Name: ".nuxt", Ecosystem: "node", Kind: KindCache},
+	{Name: ".output", Ecosystem: "node", Kind: KindCache, Manifests: nodeManifests},
+	{Name: ".turbo", Ecosystem: "node", Kind: KindCache},
+	{Name: "cache", Ecosystem: "node", Kind: KindCache, Under: ".nx", Anchored: true},
+	{Name: "cache", Ecosystem: "node", Kind: KindCache, Under: ".angular", Anchored: true},
+	{Name: ".parcel-cache", Ecosystem: "node", Kind: KindCache},
+	{Name: ".vite", Ecosystem: "node", Kind: KindCache},
+	{Name: ".astro", Ecosystem: "node", Kind: KindCache},
+	{Name: ".docusaurus", Ecosystem: "node", Kind: KindCache},
+	{Name: "storybook-static", Ecosystem: "node", Kind: KindCache, Manifests: nodeManifests},
+	{Name: "coverage", Ecosystem: "node", Kind: KindCache, Manifests: nodeManifests},
+	// babel, webpack and eslint cache here. It is deleted with its
+	// node_modules, and found on its own only when node_modules is no target.
+	{Name: ".cache", Ecosystem: "node", Kind: KindCache, Under: "node_modules", Anchored: true},
+
+	// Output folders shared by several ecosystems, told apart by manifest.
+	{Name: "target", Ecosystem: "rust", Kind: KindDependencies, Manifests: []string{"Cargo.toml"}},
+	{Name: "target", Ecosystem: "java", Kind: KindDependencies, Manifests: []string{"pom.xml"}},
+	{Name: "dist", Ecosystem: "node", Kind: KindCache, Manifests: nodeManifests},
+	{Name: "dist", Ecosystem: "deno", Kind: KindCache, Manifests: []string{"deno.json"}},
+	{Name: "dist", Ecosystem: "python", Kind: KindCache, Manifests: pythonProjects},
+	{Name: "build", Ecosystem: "node", Kind: KindCache, Manifests: nodeManifests},
+	{Name: "build", Ecosystem: "deno", Kind: KindCache, Manifests: []string{"deno.json"}},
+	{Name: "build", Ecosystem: "python", Kind: KindCache, Manifests: pythonProjects},
+	{Name: "build", Ecosystem: "java", Kind: KindCache, Manifests: []string{"build.gradle", "build.gradle.kts"}},
+	{Name: "build", Ecosystem: "cpp", Kind: KindCache, Manifests: []string{"CMakeLists.txt"}},
+	{Name: "build", Ecosystem: "dart", Kind: KindCache, Manifests: pubspecManifest},
+
+	// JVM. Gradle keeps a .gradle cache in the home directory as well as in
+	// the project; only the project one, next to a build script, is verified.
+	{Name: ".gradle", Ecosystem: "java", Kind: KindCache, Manifests: gradleScripts},
+	{Name: ".kotlin", Ecosystem: "java", Kind: KindCache, Manifests: gradleScripts},
+
+	// PHP and Ruby. Bundler installs into vendor/bundle, next to the app's
+	// own vendored files.
+	{Name: "vendor", Ecosystem: "php", Kind: KindDependencies, Manifests: composerFiles, Anchored: true},
+	{Name: "bundle", Ecosystem: "ruby", Kind: KindDependencies, Manifests: []string{"Gemfile", "Gemfile.lock"}, Under: "vendor", Anchored: true},
+
+	// Python
+	{Name: ".venv", Ecosystem: "python", Kind: KindDependencies, Signatures: []string{"pyvenv.cfg"}},
+	{Name: "__pycache__", Ecosystem: "python", Kind: KindCache, Aggregated: true},
+	{Name: ".tox", Ecosystem: "python", Kind: KindCache},
+	{Name: ".nox", Ecosystem: "python", Kind: KindCache},
+	{Name: ".pytest_cache", Ecosystem: "python", Kind: KindCache},
+	{Name: ".mypy_cache", Ecosystem: "python", Kind: KindCache},
+	{Name: ".ruff_cache", Ecosystem: "python", Kind: KindCache},
+	// Jupyter writes a fresh checkpoint the next time a notebook is saved.
+	{Name: ".ipynb_checkpoints", Ecosystem: "python", Kind: KindCache},
+	// Package metadata, regenerated by every build or editable install.
+	{Name: "*.egg-info", Ecosystem: "python", Kind: KindCache, Manifests: []string{"setup.py", "setup.cfg", "pyproject.toml"}, SrcLayout: true},
+
+	// .NET, Elixir, Dart and Haskell
+	{Name: "bin", Ecosystem: "dotnet", Kind: KindCache, Manifests: dotnetProjects, Anchored: true},
+	{Name: "obj", Ecosystem: "dotnet", Kind: KindCache, Manifests: dotnetProjects, Anchored: true},
+	{Name: "_build", Ecosystem: "elixir", Kind: KindCache, Manifests: mixProjects, Anchored: true},
+	{Name: "deps", Ecosystem: "elixir", Kind: KindDependencies, Manifests: mixProjects, Anchored: true},
+	{Name: ".dart_tool", Ecosystem: "dart", Kind: KindCache, Manifests: pubspecManifest},
+	{Name: ".stack-work", Ecosystem: "haskell", Kind: KindCache},
+	{Name: "dist-newstyle", Ecosystem: "haskell", Kind: KindCache},
+
+	// Game engines. Their folder names are common ones (and macOS has a
+	// ~/Library), so the engine's project file anchors them.
+	{Name: "Library", Ecosystem: "unity", Kind: KindCache, Manifests: unityProjects, Anchored: true},
+	{Name: "Temp", Ecosystem: "unity", Kind: KindCache, Manifests: unityProjects, Anchored: true},
+	{Name: "Obj", Ecosystem: "unity", Kind: KindCache, Manifests: unityProjects, Anchored: true},
+	{Name: "Logs", Ecosystem: "unity", Kind: KindCache, Manifests: unityProjects, Anchored: true},
+	{Name: "Intermediate", Ecosystem: "unreal", Kind: KindCache, Manifests: unrealProjects, Anchored: true},
+	{Name: "Saved", Ecosystem: "unreal", Kind: KindCache, Manifests: unrealProjects, Anchored: true},
+	{Name: "DerivedDataCache", Ecosystem: "unreal", Kind: KindCache, Manifests: unrealProjects, Anchored: true},
+	{Name: ".godot", Ecosystem: "godot", Kind: KindCache, Manifests: []string{"project.godot"}},
+}
+
+// TargetsNamed returns the entries of the target table for a folder called
+// name: those with that name or a pattern it matches, in table order.
+func TargetsNamed(name string) []Target {
+	var entries []Target
+	for _, t := range Targets {
+		if t.Name == name {
+			entries = append(entries, t)
+		} else if ok, _ := filepath.Match(t.Name, name); ok {
+			entries = append(entries, t)
+		}
+	}
+	return entries
+}
+
+// TargetNames returns the names and patterns of the target table, each once,
+// in table order.
+func TargetNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, t := range Targets {
+		if !seen[t.Name] {
+			seen[t.Name] = true
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// Signed reports whether the folder at dir has one of t's signature files.
+func (t Target) Signed(dir string) bool {
+	for _, name := range t.Signatures {
+		if FileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheTag is the file that marks a cache directory, by the Cache Directory
+// Tagging Specification (https://bford.info/cachedir/). Cargo writes one
+// into target, and many other tools into their caches.
+const CacheTag = "CACHEDIR.TAG"
+
+// cacheTagSignature is how a valid CACHEDIR.TAG must begin.
+var cacheTagSignature = []byte("Signature: 8a477f597d28d172789f06886806bc55")
+
+// HasCacheTag reports whether dir holds a CACHEDIR.TAG with a valid signature.
+func HasCacheTag(dir string) bool {
+	f, err := os.Open(filepath.Join(dir, CacheTag)) //nolint:gosec // path is constructed from a found directory
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	head := make([]byte, len(cacheTagSignature))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return false
+	}
+	return bytes.Equal(head, cacheTagSignature)
+}
+
+// Matches reports whether t matches the folder at path: whether the folder
+// sits under the directory t requires and its project, or for SrcLayout
+// entries the directory above src, has one of t's manifests.
+func (t Target) Matches(path string) bool {
+	parent := filepath.Dir(path)
+	project := parent
+	if t.Under != "" {
+		if filepath.Base(parent) != t.Under {
+			return false
+		}
+		project = filepath.Dir(parent)
+	}
+	if len(t.Manifests) == 0 {
+		return true
+	}
+	projects := []string{project}
+	if t.SrcLayout && filepath.Base(project) == "src" {
+		projects = append(projects, filepath.Dir(project))
+	}
+	for _, dir := range projects {
+		files := dirFiles{dir: dir}
+		for _, name := range t.Manifests {
+			if files.has(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasCacheTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // "" for no tag
+		want    bool
+	}{
+		{"valid", "Signature: 8a477f597d28d172789f06886806bc55\n# comment\n", true},
+		{"valid without newline", "Signature: 8a477f597d28d172789f06886806bc55", true},
+		{"wrong signature", "Signature: 00000000000000000000000000000000\n", false},
+		{"truncated", "Signature: 8a477f", false},
+		{"no tag", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != "" {
+				if err := os.WriteFile(filepath.Join(dir, CacheTag), []byte(tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := HasCacheTag(dir); got != tt.want {
+				t.Errorf("HasCacheTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
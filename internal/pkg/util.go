@@ -1,6 +1,11 @@
 package pkg
 
-import "os"
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
 
 // FileExists reports whether a path exists and is a regular file (not a directory).
 func FileExists(path string) bool {
@@ -19,3 +24,23 @@ func DirExists(path string) bool {
 	}
 	return info.IsDir()
 }
+
+// stopGrace is how long a child process gets to exit after being interrupted
+// before it is killed.
+const stopGrace = 5 * time.Second
+
+// commandContext is like exec.CommandContext, but when ctx is canceled it
+// first interrupts the child, letting package managers clean up after
+// themselves, and only kills it if it is still running after stopGrace.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			// Windows cannot deliver interrupts to other processes.
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = stopGrace
+	return cmd
+}
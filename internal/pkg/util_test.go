@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCommandContextStopsChild(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX sleep")
+	}
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := commandContext(ctx, "sleep", "30").Run()
+	if err == nil {
+		t.Fatal("Run() error = nil, want the interrupted command to fail")
+	}
+	if elapsed := time.Since(start); elapsed > stopGrace {
+		t.Errorf("child ran for %v after cancel, want it stopped by the interrupt", elapsed)
+	}
+}
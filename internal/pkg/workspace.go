@@ -0,0 +1,225 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Workspace is a monorepo root that declares member packages, such as a
+// pnpm, npm or yarn workspace or a Cargo workspace. Dependencies of every
+// member are installed from the root.
+type Workspace struct {
+	Root    string
+	PM      PackageManager // the package manager that owns the workspace
+	Members []string       // member globs, relative to Root
+	Exclude []string       // globs of directories that are not members
+}
+
+// ReadWorkspace reports whether dir is a workspace root, reading
+// pnpm-workspace.yaml, the "workspaces" field of package.json and the
+// [workspace] table of Cargo.toml, in that order.
+func ReadWorkspace(dir string) (Workspace, bool) {
+	if members, exclude, ok := readPnpmWorkspace(filepath.Join(dir, "pnpm-workspace.yaml")); ok {
+		return Workspace{Root: dir, PM: Pnpm, Members: members, Exclude: exclude}, true
+	}
+	if members, ok := readPackageJSONWorkspaces(filepath.Join(dir, "package.json")); ok {
+		return Workspace{Root: dir, PM: nodeManager(dir), Members: members}, true
+	}
+	if members, exclude, ok := readCargoWorkspace(filepath.Join(dir, "Cargo.toml")); ok {
+		return Workspace{Root: dir, PM: Cargo, Members: members, Exclude: exclude}, true
+	}
+	return Workspace{}, false
+}
+
+// FindWorkspace returns the workspace dir belongs to: the nearest workspace
+// declared in dir or above it, if dir is its root or one of its members.
+// The root is relative if dir is and the root is below the working directory.
+func FindWorkspace(dir string) (Workspace, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Workspace{}, false
+	}
+
+	// rel walks up alongside abs, in the form dir was given in.
+	rel := filepath.Clean(dir)
+	for {
+		if ws, ok := ReadWorkspace(abs); ok {
+			if !ws.Includes(dir) {
+				return Workspace{}, false
+			}
+			ws.Root = rel
+			return ws, true
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return Workspace{}, false
+		}
+		if rel == "." || filepath.Base(rel) == ".." {
+			rel = parent
+		} else {
+			rel = filepath.Dir(rel)
+		}
+		abs = parent
+	}
+}
+
+// Includes reports whether dir is the workspace root or one of its members.
+func (w Workspace) Includes(dir string) bool {
+	root, err := filepath.Abs(w.Root)
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	if abs == root {
+		return true
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range w.Exclude {
+		if matchGlob(pattern, rel) {
+			return false
+		}
+	}
+	for _, pattern := range w.Members {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated relative path against a workspace
+// glob, where * matches within one path segment and ** matches any number
+// of segments.
+func matchGlob(pattern, rel string) bool {
+	pattern = strings.Trim(strings.TrimPrefix(pattern, "./"), "/")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// readPnpmWorkspace reads the packages list of a pnpm-workspace.yaml. Globs
+// starting with ! are exclusions.
+func readPnpmWorkspace(file string) (members, exclude []string, ok bool) {
+	f, err := os.Open(file) //nolint:gosec // path is constructed from a scanned project directory
+	if err != nil {
+		return nil, nil, false
+	}
+	defer func() { _ = f.Close() }()
+
+	// The file is YAML, but only the top-level "packages" list matters, so a
+	// line scanner is enough and saves a YAML dependency.
+	inPackages := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+		if !inPackages || !strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+
+		glob := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+		if i := strings.Index(glob, " #"); i >= 0 {
+			glob = strings.TrimSpace(glob[:i])
+		}
+		glob = strings.Trim(glob, `"'`)
+		if excluded, ok := strings.CutPrefix(glob, "!"); ok {
+			exclude = append(exclude, excluded)
+		} else if glob != "" {
+			members = append(members, glob)
+		}
+	}
+	return members, exclude, true
+}
+
+// readPackageJSONWorkspaces reads the "workspaces" field of a package.json,
+// either a list of globs or an object with a "packages" list.
+func readPackageJSONWorkspaces(file string) ([]string, bool) {
+	data, err := os.ReadFile(file) //nolint:gosec // path is constructed from a scanned project directory
+	if err != nil {
+		return nil, false
+	}
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(data, &manifest) != nil || len(manifest.Workspaces) == 0 {
+		return nil, false
+	}
+
+	var members []string
+	if json.Unmarshal(manifest.Workspaces, &members) == nil {
+		return members, true
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if json.Unmarshal(manifest.Workspaces, &object) == nil && object.Packages != nil {
+		return object.Packages, true
+	}
+	return nil, false
+}
+
+// readCargoWorkspace reads the [workspace] table of a Cargo.toml.
+func readCargoWorkspace(file string) (members, exclude []string, ok bool) {
+	var manifest struct {
+		Workspace *struct {
+			Members []string `toml:"members"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"workspace"`
+	}
+	if _, err := toml.DecodeFile(file, &manifest); err != nil || manifest.Workspace == nil {
+		return nil, nil, false
+	}
+	return manifest.Workspace.Members, manifest.Workspace.Exclude, true
+}
+
+// nodeManager returns the JavaScript package manager whose lockfile is in
+// dir, or npm if there is none.
+func nodeManager(dir string) PackageManager {
+	switch pm := DetectManager(dir); pm {
+	case Npm, Pnpm, Yarn, Bun:
+		return pm
+	default:
+		return Npm
+	}
+}
@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindWorkspace(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"pnpm/pnpm-workspace.yaml": "packages:\n  - 'packages/*'\n  - \"apps/**\" # every app\n  - '!apps/legacy'\ncatalog:\n  react: ^18\n",
+		"npm/package.json":         `{"name": "mono", "workspaces": ["libs/*"]}`,
+		"yarn/package.json":        `{"workspaces": {"packages": ["pkgs/*"]}}`,
+		"yarn/yarn.lock":           "",
+		"cargo/Cargo.toml":         "[workspace]\nmembers = [\"crates/*\"]\nexclude = [\"crates/scratch\"]\n",
+		"plain/package.json":       `{"name": "solo"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		dir      string
+		wantRoot string // "" if dir is in no workspace
+		wantPM   PackageManager
+	}{
+		{"pnpm", "pnpm", Pnpm},
+		{"pnpm/packages/ui", "pnpm", Pnpm},
+		{"pnpm/apps/web/site", "pnpm", Pnpm},
+		{"pnpm/apps/legacy", "", ""},
+		{"pnpm/tools/script", "", ""},
+		{"npm/libs/core", "npm", Npm},
+		{"yarn/pkgs/a", "yarn", Yarn},
+		{"cargo/crates/cli", "cargo", Cargo},
+		{"cargo/crates/scratch", "", ""},
+		{"plain/sub", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			ws, ok := FindWorkspace(filepath.Join(root, filepath.FromSlash(tt.dir)))
+			if tt.wantRoot == "" {
+				if ok {
+					t.Errorf("FindWorkspace() = %+v, want none", ws)
+				}
+				return
+			}
+			want := filepath.Join(root, tt.wantRoot)
+			if !ok || ws.Root != want || ws.PM != tt.wantPM {
+				t.Errorf("FindWorkspace() = %+v, %v, want root %s, %s", ws, ok, want, tt.wantPM)
+			}
+		})
+	}
+}
+
+func TestFindWorkspaceRelative(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "packages", "ui"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pnpm-workspace.yaml"), []byte("packages:\n  - packages/*\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(root)
+
+	ws, ok := FindWorkspace(filepath.Join("packages", "ui"))
+	if !ok || ws.Root != "." {
+		t.Errorf("FindWorkspace(packages/ui) = %+v, %v, want root .", ws, ok)
+	}
+}
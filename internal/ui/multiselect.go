@@ -2,7 +2,9 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,6 +16,12 @@ type Item struct {
 	Label    string
 	Detail   string // e.g. formatted size
 	Selected bool
+	// Group, if set, lists the item under a header shared by every item of
+	// the same Group; toggling the header toggles them all.
+	Group string
+	// ID identifies the item to the caller. Items may be reordered to keep
+	// groups together, so use it rather than the item's position.
+	ID int
 }
 
 // Result holds the outcome of the multi-select interaction.
@@ -29,6 +37,93 @@ type model struct {
 	showHelp bool
 	done     bool
 	canceled bool
+
+	stream  <-chan Item // items still arriving, nil for a static list
+	loading bool        // stream is not closed yet
+}
+
+// row is one line of the list: a group header when item is -1, otherwise
+// the item at that index.
+type row struct {
+	group string
+	item  int
+}
+
+// rows returns the lines of the list, with a header before each group.
+func (m model) rows() []row {
+	rows := make([]row, 0, len(m.items))
+	for i, item := range m.items {
+		if item.Group != "" && (i == 0 || m.items[i-1].Group != item.Group) {
+			rows = append(rows, row{group: item.Group, item: -1})
+		}
+		rows = append(rows, row{group: item.Group, item: i})
+	}
+	return rows
+}
+
+// add inserts item after the last item of its group, or at the end, keeping
+// the cursor on the line it was on.
+func (m *model) add(item Item) {
+	rows := m.rows()
+	var current row
+	hasCurrent := m.cursor < len(rows)
+	if hasCurrent {
+		current = rows[m.cursor]
+	}
+
+	pos := len(m.items)
+	if item.Group != "" {
+		for j := len(m.items) - 1; j >= 0; j-- {
+			if m.items[j].Group == item.Group {
+				pos = j + 1
+				break
+			}
+		}
+	}
+	m.items = slices.Insert(m.items, pos, item)
+
+	if !hasCurrent {
+		return
+	}
+	if current.item >= pos {
+		current.item++
+	}
+	for i, r := range m.rows() {
+		if r == current {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// groupState reports whether every item of group, and whether some, is selected.
+func (m model) groupState(group string) (all, some bool) {
+	all = true
+	for _, item := range m.items {
+		if item.Group != group {
+			continue
+		}
+		all = all && item.Selected
+		some = some || item.Selected
+	}
+	return all, some
+}
+
+// itemMsg delivers an item read from the stream.
+type itemMsg Item
+
+// streamDoneMsg reports that the stream was closed.
+type streamDoneMsg struct{}
+
+// waitForItem reads the next item from stream.
+func waitForItem(stream <-chan Item) tea.Cmd {
+	return func() tea.Msg {
+		item, ok := <-stream
+		if !ok {
+			return streamDoneMsg{}
+		}
+		return itemMsg(item)
+	}
 }
 
 // Styles
@@ -55,6 +150,14 @@ var (
 	itemLabelDimStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#9CA3AF"))
 
+	groupStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#E5E7EB")).
+			Bold(true)
+
+	groupDimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#D1D5DB")).
+			Bold(true)
+
 	detailStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#60A5FA"))
 
@@ -90,6 +193,9 @@ func initialModel(title string, items []Item) model {
 }
 
 func (m model) Init() tea.Cmd {
+	if m.stream != nil {
+		return waitForItem(m.stream)
+	}
 	return nil
 }
 
@@ -97,6 +203,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
+	case itemMsg:
+		m.add(Item(msg))
+		return m, waitForItem(m.stream)
+	case streamDoneMsg:
+		m.loading = false
+		// Nothing to choose from: finish without prompting.
+		if len(m.items) == 0 {
+			m.done = true
+			return m, tea.Quit
+		}
 	}
 
 	return m, nil
@@ -111,6 +227,10 @@ func (m model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "?":
 		m.showHelp = !m.showHelp
 	case "enter":
+		// Confirming is only possible once every item has arrived.
+		if m.loading {
+			return m, nil
+		}
 		m.done = true
 		return m, tea.Quit
 	case "q", "esc", "ctrl+c":
@@ -123,26 +243,46 @@ func (m model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) handleNavigation(key string) {
+	rows := m.rows()
+	if len(rows) == 0 {
+		return
+	}
+
 	switch key {
 	case "up", "k":
 		if m.cursor > 0 {
 			m.cursor--
 		}
 	case "down", "j":
-		if m.cursor < len(m.items)-1 {
+		if m.cursor < len(rows)-1 {
 			m.cursor++
 		}
 	case "home", "g":
 		m.cursor = 0
 	case "end", "G":
-		m.cursor = len(m.items) - 1
+		m.cursor = len(rows) - 1
 	}
 }
 
 func (m *model) handleSelection(key string) {
+	if len(m.items) == 0 {
+		return
+	}
+
 	switch key {
 	case " ":
-		m.items[m.cursor].Selected = !m.items[m.cursor].Selected
+		r := m.rows()[m.cursor]
+		if r.item >= 0 {
+			m.items[r.item].Selected = !m.items[r.item].Selected
+			break
+		}
+		// A header selects its whole group, or clears it if already all selected.
+		all, _ := m.groupState(r.group)
+		for i := range m.items {
+			if m.items[i].Group == r.group {
+				m.items[i].Selected = !all
+			}
+		}
 	case "a":
 		for i := range m.items {
 			m.items[i].Selected = true
@@ -169,13 +309,19 @@ func (m model) View() string {
 	b.WriteString(titleStyle.Render(m.title))
 	b.WriteString("\n")
 
-	// Items
-	for i, item := range m.items {
+	// Items, each group under its header
+	for i, r := range m.rows() {
 		cursor := "  "
 		if i == m.cursor {
 			cursor = cursorStyle.Render("▸ ")
 		}
 
+		if r.item < 0 {
+			fmt.Fprintf(&b, "%s%s\n", cursor, m.viewHeader(r.group, i == m.cursor))
+			continue
+		}
+		item := m.items[r.item]
+
 		checkbox := unselectedStyle.Render("[ ]")
 		if item.Selected {
 			checkbox = selectedStyle.Render("[✓]")
@@ -191,7 +337,11 @@ func (m model) View() string {
 			detail = " " + detailStyle.Render(item.Detail)
 		}
 
-		fmt.Fprintf(&b, "%s%s %s%s\n", cursor, checkbox, label, detail)
+		indent := ""
+		if item.Group != "" {
+			indent = "  "
+		}
+		fmt.Fprintf(&b, "%s%s%s %s%s\n", cursor, indent, checkbox, label, detail)
 	}
 
 	// Status bar
@@ -201,9 +351,11 @@ func (m model) View() string {
 			selected++
 		}
 	}
-	b.WriteString(statusBarStyle.Render(
-		fmt.Sprintf("  %d/%d selected", selected, len(m.items)),
-	))
+	status := fmt.Sprintf("  %d/%d selected", selected, len(m.items))
+	if m.loading {
+		status += " · scanning..."
+	}
+	b.WriteString(statusBarStyle.Render(status))
 	b.WriteString("\n")
 
 	// Help
@@ -215,11 +367,11 @@ func (m model) View() string {
 			{"↑/k", "move up"},
 			{"↓/j", "move down"},
 			{"g/G", "go to first/last"},
-			{"space", "toggle item"},
+			{"space", "toggle item, or whole group on a header"},
 			{"a", "select all"},
 			{"n", "deselect all"},
 			{"i", "invert selection"},
-			{"enter", "confirm"},
+			{"enter", "confirm (once scanning is done)"},
 			{"q/esc", "cancel"},
 		}
 		for _, h := range helpItems {
@@ -236,24 +388,94 @@ func (m model) View() string {
 	return b.String()
 }
 
+// viewHeader renders the header line of group.
+func (m model) viewHeader(group string, underCursor bool) string {
+	all, some := m.groupState(group)
+	checkbox := unselectedStyle.Render("[ ]")
+	switch {
+	case all:
+		checkbox = selectedStyle.Render("[✓]")
+	case some:
+		checkbox = selectedStyle.Render("[-]")
+	}
+
+	var count int
+	for _, item := range m.items {
+		if item.Group == group {
+			count++
+		}
+	}
+
+	label := groupStyle.Render(group)
+	if !underCursor {
+		label = groupDimStyle.Render(group)
+	}
+	return fmt.Sprintf("%s %s %s", checkbox, label, detailStyle.Render(fmt.Sprintf("(%d folders)", count)))
+}
+
 // RunMultiSelect launches an interactive multi-select prompt and returns the result.
-// All items are pre-selected by default.
-func RunMultiSelect(title string, items []Item) (Result, error) {
+// All items are pre-selected by default. Canceling ctx cancels the prompt.
+func RunMultiSelect(ctx context.Context, title string, items []Item) (Result, error) {
 	m := initialModel(title, items)
-	p := tea.NewProgram(m)
+	p := tea.NewProgram(m, tea.WithContext(ctx))
+
+	finalModel, err := p.Run()
+	if ctx.Err() != nil {
+		return Result{Canceled: true}, nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run multi-select: %w", err)
+	}
+
+	fm, ok := finalModel.(model)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected model type")
+	}
+
+	return Result{
+		Items:    fm.items,
+		Canceled: fm.canceled,
+	}, nil
+}
+
+// RunMultiSelectStream launches an interactive multi-select that starts empty
+// and adds items as they arrive on stream. The selection can be confirmed once
+// stream is closed; if it closes without delivering any item, the prompt ends
+// on its own with an empty result. If the user cancels early, the rest of
+// stream is drained in the background so its producer is not blocked.
+// Canceling ctx cancels the prompt.
+func RunMultiSelectStream(ctx context.Context, title string, stream <-chan Item) (Result, error) {
+	m := initialModel(title, nil)
+	m.stream = stream
+	m.loading = true
+	p := tea.NewProgram(m, tea.WithContext(ctx))
 
 	finalModel, err := p.Run()
+	if ctx.Err() != nil {
+		go drain(stream)
+		return Result{Canceled: true}, nil
+	}
 	if err != nil {
+		go drain(stream)
 		return Result{}, fmt.Errorf("failed to run multi-select: %w", err)
 	}
 
 	fm, ok := finalModel.(model)
 	if !ok {
+		go drain(stream)
 		return Result{}, fmt.Errorf("unexpected model type")
 	}
+	if fm.loading {
+		go drain(stream)
+	}
 
 	return Result{
 		Items:    fm.items,
 		Canceled: fm.canceled,
 	}, nil
 }
+
+func drain(stream <-chan Item) {
+	for range stream {
+	}
+}
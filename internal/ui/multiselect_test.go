@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGroupedItemsStayTogether(t *testing.T) {
+	m := initialModel("pick", nil)
+	for _, item := range []Item{
+		{Label: "a/node_modules", Group: "a", ID: 0},
+		{Label: "b/target", Group: "b", ID: 1},
+		{Label: "a/.next", Group: "a", ID: 2},
+	} {
+		m.add(item)
+	}
+
+	var got []int
+	for _, item := range m.items {
+		got = append(got, item.ID)
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 2 || got[2] != 1 {
+		t.Fatalf("item order = %v, want [0 2 1]", got)
+	}
+	if rows := m.rows(); len(rows) != 5 || rows[0].item != -1 || rows[3].item != -1 {
+		t.Fatalf("rows = %+v, want a header before each group", rows)
+	}
+}
+
+func TestHeaderTogglesGroup(t *testing.T) {
+	m := initialModel("pick", []Item{
+		{Label: "x", Group: "a", Selected: true},
+		{Label: "y", Group: "a"},
+		{Label: "z", Group: "b", Selected: true},
+	})
+
+	// The cursor starts on group a's header; a partly selected group becomes fully selected.
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	next, _ := m.Update(space)
+	m = next.(model)
+	if !m.items[0].Selected || !m.items[1].Selected || !m.items[2].Selected {
+		t.Fatalf("after toggling header a: %+v, want all selected", m.items)
+	}
+
+	next, _ = m.Update(space)
+	m = next.(model)
+	if m.items[0].Selected || m.items[1].Selected || !m.items[2].Selected {
+		t.Errorf("after toggling header a again: %+v, want only group b selected", m.items)
+	}
+}
+
+func TestAddKeepsCursorOnItsRow(t *testing.T) {
+	m := initialModel("pick", nil)
+	m.add(Item{Label: "b/target", Group: "b", ID: 1})
+	m.add(Item{Label: "c/target", Group: "c", ID: 2})
+	m.cursor = 3 // c/target
+
+	m.add(Item{Label: "b/dist", Group: "b", ID: 3})
+	if r := m.rows()[m.cursor]; r.item < 0 || m.items[r.item].ID != 2 {
+		t.Errorf("cursor moved to %+v, want it to stay on c/target", r)
+	}
+}
@@ -0,0 +1,102 @@
+package pumu
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"pumu/internal/pkg"
+)
+
+// aggregateDir stands for "any directories" in the path of an aggregate,
+// <project>/**/<name>.
+const aggregateDir = "**"
+
+// aggregator collects the aggregated targets found in the walk of one root
+// and groups them by project. It is safe for concurrent use.
+type aggregator struct {
+	root string
+
+	mu         sync.Mutex
+	projects   map[string]string   // directory -> its project directory
+	members    map[string][]string // aggregate path -> folders
+	ecosystems map[string]string   // aggregate path -> ecosystem
+}
+
+func newAggregator(root string) *aggregator {
+	return &aggregator{
+		root:       root,
+		projects:   make(map[string]string),
+		members:    make(map[string][]string),
+		ecosystems: make(map[string]string),
+	}
+}
+
+// add records the target at path.
+func (a *aggregator) add(path, ecosystem string) {
+	key := filepath.Join(a.projectOf(filepath.Dir(path)), aggregateDir, filepath.Base(path))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.members[key] = append(a.members[key], path)
+	a.ecosystems[key] = ecosystem
+}
+
+// projectOf returns the nearest directory from dir up to the root whose
+// package manager is known, or the root if there is none. Results are
+// memoized for dir and every directory passed on the way up. The lock is
+// only held around the memo, not while detecting managers, so workers
+// racing up the same directories may both detect them, to the same result.
+func (a *aggregator) projectOf(dir string) string {
+	var visited []string
+	project := a.root
+	for d := dir; ; d = filepath.Dir(d) {
+		if p, ok := a.memoized(d); ok {
+			project = p
+			break
+		}
+		visited = append(visited, d)
+		if pkg.DetectManager(d) != pkg.Unknown {
+			project = d
+			break
+		}
+		if d == a.root || d == filepath.Dir(d) {
+			break
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, d := range visited {
+		a.projects[d] = project
+	}
+	return project
+}
+
+// memoized returns the project projectOf found earlier for dir.
+func (a *aggregator) memoized(dir string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p, ok := a.projects[dir]
+	return p, ok
+}
+
+// folders returns one folder per project and name, sorted by path. A
+// project with a single folder of a name gets that folder rather than an
+// aggregate.
+func (a *aggregator) folders() []TargetFolder {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	folders := make([]TargetFolder, 0, len(a.members))
+	for key, members := range a.members {
+		if len(members) == 1 {
+			folders = append(folders, TargetFolder{Path: members[0], Ecosystem: a.ecosystems[key]})
+			continue
+		}
+		sort.Strings(members)
+		folders = append(folders, TargetFolder{Path: key, Ecosystem: a.ecosystems[key], Members: members})
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Path < folders[j].Path })
+	return folders
+}
@@ -0,0 +1,65 @@
+package pumu
+
+// EventKind says what an Event reports.
+type EventKind string
+
+// Kinds of events sent to Options.OnEvent.
+const (
+	// TargetFound: a target folder was found and is about to be measured.
+	// Folder is set, without sizes.
+	TargetFound EventKind = "target_found"
+	// FolderSized: a target folder was measured and passed the size filter.
+	// Folder is set.
+	FolderSized EventKind = "folder_sized"
+	// FolderAnalyzed: a measured folder was scored by Analyze. Folder and
+	// Result are set.
+	FolderAnalyzed EventKind = "folder_analyzed"
+
+	// DeleteStarted: a folder is about to be removed. Folder is set.
+	DeleteStarted EventKind = "delete_started"
+	// DeleteFinished: a folder was removed. Folder is set.
+	DeleteFinished EventKind = "delete_finished"
+	// DeleteFailed: a folder could not be removed. Folder and Err are set.
+	DeleteFailed EventKind = "delete_failed"
+
+	// HealthChecked: a project's dependencies were checked. Project and
+	// Health are set.
+	HealthChecked EventKind = "health_checked"
+	// ReinstallStarted: a project's dependencies are about to be installed.
+	// Project is set.
+	ReinstallStarted EventKind = "reinstall_started"
+	// ReinstallFinished: a project's dependencies were installed. Project is set.
+	ReinstallFinished EventKind = "reinstall_finished"
+	// ReinstallFailed: installing a project's dependencies failed. Project
+	// and Err are set.
+	ReinstallFailed EventKind = "reinstall_failed"
+)
+
+// Event reports progress while a Scanner works. Only the fields documented
+// for its Kind are set.
+type Event struct {
+	Kind    EventKind
+	Folder  TargetFolder
+	Result  PruneResult
+	Project Project
+	Health  HealthResult
+	Err     error
+}
+
+// emit sends e to the OnEvent callback, one call at a time.
+func (s *Scanner) emit(e Event) {
+	if s.opts.OnEvent == nil {
+		return
+	}
+	s.emitMu.Lock()
+	defer s.emitMu.Unlock()
+	s.opts.OnEvent(e)
+}
+
+// deleteEvent returns the DeleteFinished or DeleteFailed event for removing f.
+func deleteEvent(f TargetFolder, err error) Event {
+	if err != nil {
+		return Event{Kind: DeleteFailed, Folder: f, Err: err}
+	}
+	return Event{Kind: DeleteFinished, Folder: f}
+}
@@ -0,0 +1,156 @@
+package pumu
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"pumu/internal/pkg"
+)
+
+// Filter narrows the folders a scan returns. The zero value keeps everything;
+// each set field must match for a folder to be kept.
+type Filter struct {
+	// MinSize keeps folders reclaiming at least this many bytes.
+	MinSize int64
+	// OlderThan keeps folders whose project has been untouched for at least
+	// this long: neither the folder nor its project's lockfile was modified
+	// more recently.
+	OlderThan time.Duration
+	// Types keeps folders with one of these names, e.g. "node_modules", or
+	// matching one of these target patterns, e.g. "*.egg-info". ".venv"
+	// stands for every virtual environment, whatever its name.
+	Types []string
+	// Ecosystems keeps folders of one of these ecosystems, e.g. "node".
+	// Package manager names such as "cargo" stand for their ecosystem.
+	Ecosystems []string
+}
+
+// matchesFound reports whether a folder just found passes every filter that
+// does not need its size.
+func (f *Filter) matchesFound(folder TargetFolder) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, typeOf(folder.Path)) {
+		return false
+	}
+	if len(f.Ecosystems) > 0 && !f.matchesEcosystem(folder.Ecosystem) {
+		return false
+	}
+	if f.OlderThan > 0 && folderAge(folder) < f.OlderThan {
+		return false
+	}
+	return true
+}
+
+// matchesSized reports whether a measured folder passes the size filter.
+func (f *Filter) matchesSized(folder TargetFolder) bool {
+	return folder.Size >= f.MinSize
+}
+
+func (f *Filter) matchesEcosystem(ecosystem string) bool {
+	for _, name := range f.Ecosystems {
+		if e, ok := managerEcosystems[PackageManager(name)]; ok {
+			name = e
+		}
+		if name == ecosystem {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEcosystem reports whether name is something Filter.Ecosystems can
+// match: the ecosystem of a target or of a package manager, e.g. "node", or
+// the name of a package manager, e.g. "cargo".
+func IsEcosystem(name string) bool {
+	if _, ok := managerEcosystems[PackageManager(name)]; ok {
+		return true
+	}
+	for _, e := range managerEcosystems {
+		if e == name {
+			return true
+		}
+	}
+	for _, t := range pkg.Targets {
+		if t.Ecosystem == name {
+			return true
+		}
+	}
+	return false
+}
+
+// folderAge returns how long ago the folder f, any of its members if it is
+// an aggregate, or its project's lockfile was last modified, whichever is
+// most recent. A folder that cannot be read has age 0, so it is never taken
+// for an old one.
+func folderAge(f TargetFolder) time.Duration {
+	age := time.Duration(math.MaxInt64)
+	for _, path := range f.Paths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0
+		}
+		age = min(age, time.Since(info.ModTime()))
+	}
+
+	project := projectDirOf(f.Path)
+	if pm := pkg.DetectManager(project); pm != pkg.Unknown {
+		if lockAge := pkg.LockfileAge(project, pm); lockAge > 0 && lockAge < age {
+			age = lockAge
+		}
+	}
+	return age
+}
+
+// sizeUnits maps size suffixes to their byte multiples. Units are binary,
+// like the sizes pumu prints, so "1GB" is 1024³ bytes.
+var sizeUnits = map[string]int64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+// ParseSize parses a size such as "200MB", "1.5GB" or "4096" into bytes.
+func ParseSize(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(trimmed)
+	}
+
+	n, err := strconv.ParseFloat(trimmed[:i], 64)
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(trimmed[i:]))]
+	if err != nil || !ok || n < 0 || n*float64(unit) > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: want e.g. 500KB, 200MB or 1GB", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// ParseAge parses an age such as "90d", "2w" or "36h". Days and weeks are
+// accepted on top of the units of time.ParseDuration.
+func ParseAge(s string) (time.Duration, error) {
+	trimmed := strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(trimmed, suffix); ok {
+			days, err := strconv.ParseFloat(n, 64)
+			if err != nil || days < 0 {
+				break
+			}
+			return time.Duration(days * float64(unit)), nil
+		}
+	}
+
+	d, err := time.ParseDuration(trimmed)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q: want e.g. 36h, 60d or 2w", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,120 @@
+package pumu
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"4096", 4096, false},
+		{"500KB", 500 << 10, false},
+		{"200MB", 200 << 20, false},
+		{"200 mb", 200 << 20, false},
+		{"1.5GB", 3 << 29, false},
+		{"2GiB", 2 << 30, false},
+		{"1T", 1 << 40, false},
+		{"", 0, true},
+		{"MB", 0, true},
+		{"3XB", 0, true},
+		{"-1GB", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestIsEcosystem(t *testing.T) {
+	for _, name := range []string{"node", "rust", "go", "unity", "cargo", "pnpm", "conda"} {
+		if !IsEcosystem(name) {
+			t.Errorf("IsEcosystem(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"rsut", "", "Node", "unknown"} {
+		if IsEcosystem(name) {
+			t.Errorf("IsEcosystem(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"d", 0, true},
+		{"-3d", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAge(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, %v, want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestScanFilters(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "web/node_modules", "old/node_modules", "rust/target", "stale/node_modules")
+	writeFile(t, root, "web/node_modules/big.js", string(make([]byte, 64<<10)))
+	writeFile(t, root, "rust/Cargo.toml", "")
+	writeFile(t, root, "stale/package-lock.json", "{}")
+
+	old := time.Now().Add(-100 * 24 * time.Hour)
+	for _, p := range []string{"old/node_modules", "rust/target", "stale/node_modules"} {
+		if err := os.Chtimes(filepath.Join(root, p), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"none", Filter{}, []string{"old/node_modules", "rust/target", "stale/node_modules", "web/node_modules"}},
+		{"min size", Filter{MinSize: 32 << 10}, []string{"web/node_modules"}},
+		// stale's lockfile was just written, so its project is not old.
+		{"older than", Filter{OlderThan: 90 * 24 * time.Hour}, []string{"old/node_modules", "rust/target"}},
+		{"type", Filter{Types: []string{"target"}}, []string{"rust/target"}},
+		{"package manager as ecosystem", Filter{Ecosystems: []string{"cargo"}}, []string{"rust/target"}},
+		{"combined", Filter{Types: []string{"node_modules"}, OlderThan: 90 * 24 * time.Hour}, []string{"old/node_modules"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Options{NoCache: true, Filter: tt.filter})
+			folders, report, err := s.Scan(context.Background(), []string{root})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+
+			got := relPaths(t, root, folders)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+			if report.Filtered != 4-len(tt.want) {
+				t.Errorf("report.Filtered = %d, want %d", report.Filtered, 4-len(tt.want))
+			}
+		})
+	}
+}
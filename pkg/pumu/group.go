@@ -0,0 +1,129 @@
+package pumu
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"pumu/internal/pkg"
+)
+
+// GroupBy says how GroupFolders groups folders.
+type GroupBy string
+
+// Ways to group folders.
+const (
+	// GroupByProject groups folders by the project they belong to: their
+	// workspace root, if declared under their scan root, or else the nearest
+	// directory above them, up to their scan root, whose package manager is
+	// known, or else their parent directory.
+	GroupByProject GroupBy = "project"
+	// GroupByEcosystem groups folders by ecosystem.
+	GroupByEcosystem GroupBy = "ecosystem"
+	// GroupByParent groups folders by their parent directory.
+	GroupByParent GroupBy = "parent"
+)
+
+// ParseGroupBy parses "project", "ecosystem" or "parent".
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch by := GroupBy(s); by {
+	case GroupByProject, GroupByEcosystem, GroupByParent:
+		return by, nil
+	default:
+		return "", fmt.Errorf("invalid grouping %q: want project, ecosystem or parent", s)
+	}
+}
+
+// Group is a set of folders sharing a project, ecosystem or parent directory.
+type Group struct {
+	// Key is the project or parent directory, or the ecosystem name ("" if
+	// unknown).
+	Key string
+	// PM is the project's package manager when grouping by project, or ""
+	// if the project's package manager is unknown.
+	PM       PackageManager
+	Folders  []TargetFolder // sorted by size, largest first
+	Size     int64          // total reclaimable bytes
+	Apparent int64          // total apparent bytes
+}
+
+// GroupFolders groups folders found under roots, largest group first.
+func GroupFolders(folders []TargetFolder, by GroupBy, roots []string) []Group {
+	var groups []Group
+	index := make(map[string]int)
+	workspaces := newWorkspaceFinder(roots)
+
+	for _, f := range folders {
+		key, pm := groupKey(f, by, roots, workspaces)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Key: key, PM: pm})
+		}
+		g := &groups[i]
+		g.Folders = append(g.Folders, f)
+		g.Size += f.Size
+		g.Apparent += f.Apparent
+	}
+
+	for _, g := range groups {
+		sort.SliceStable(g.Folders, func(i, j int) bool { return g.Folders[i].Size > g.Folders[j].Size })
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Size != groups[j].Size {
+			return groups[i].Size > groups[j].Size
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+// GroupKey returns the key of the group folder f, found under roots, falls
+// in, and the package manager of its project when grouping by project (""
+// otherwise). Only workspaces declared under roots count.
+func GroupKey(f TargetFolder, by GroupBy, roots []string) (string, PackageManager) {
+	return groupKey(f, by, roots, newWorkspaceFinder(roots))
+}
+
+// groupKey is GroupKey, looking workspaces up with workspaces.
+func groupKey(f TargetFolder, by GroupBy, roots []string, workspaces *workspaceFinder) (string, PackageManager) {
+	switch by {
+	case GroupByEcosystem:
+		return f.Ecosystem, ""
+	case GroupByParent:
+		if f.Members != nil {
+			return projectDirOf(f.Path), ""
+		}
+		return filepath.Dir(f.Path), ""
+	}
+
+	parent := projectDirOf(f.Path)
+	if ws, ok := workspaces.find(parent); ok {
+		return ws.Root, ws.PM
+	}
+	stop := rootOf(parent, roots)
+	for dir := parent; ; dir = filepath.Dir(dir) {
+		if pm := pkg.DetectManager(dir); pm != pkg.Unknown {
+			return dir, pm
+		}
+		if dir == stop || dir == filepath.Dir(dir) {
+			return parent, ""
+		}
+	}
+}
+
+// rootOf returns the longest of roots containing path, or path itself if none does.
+func rootOf(path string, roots []string) string {
+	best := ""
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		if (root == path || isWithin(root, path)) && len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return path
+	}
+	return best
+}
@@ -0,0 +1,72 @@
+package pumu
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGroupFolders(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "web/node_modules", "web/.next", "web/apps/site/node_modules", "rs/target", "loose/node_modules")
+	writeFile(t, root, "web/package-lock.json", "{}")
+	writeFile(t, root, "rs/Cargo.toml", "")
+
+	folder := func(rel, ecosystem string, size int64) TargetFolder {
+		return TargetFolder{Path: filepath.Join(root, filepath.FromSlash(rel)), Ecosystem: ecosystem, Size: size}
+	}
+	folders := []TargetFolder{
+		folder("loose/node_modules", "node", 5),
+		folder("rs/target", "rust", 40),
+		folder("web/.next", "node", 10),
+		folder("web/apps/site/node_modules", "node", 20),
+		folder("web/node_modules", "node", 30),
+	}
+
+	type group struct {
+		key     string
+		pm      PackageManager
+		size    int64
+		folders []string
+	}
+	tests := []struct {
+		by   GroupBy
+		want []group
+	}{
+		{GroupByProject, []group{
+			// apps/site has no lockfile of its own, so it belongs to web.
+			{"web", "npm", 60, []string{"web/node_modules", "web/apps/site/node_modules", "web/.next"}},
+			{"rs", "cargo", 40, []string{"rs/target"}},
+			{"loose", "", 5, []string{"loose/node_modules"}},
+		}},
+		{GroupByParent, []group{
+			{"rs", "", 40, []string{"rs/target"}},
+			{"web", "", 40, []string{"web/node_modules", "web/.next"}},
+			{"web/apps/site", "", 20, []string{"web/apps/site/node_modules"}},
+			{"loose", "", 5, []string{"loose/node_modules"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.by), func(t *testing.T) {
+			var got []group
+			for _, g := range GroupFolders(folders, tt.by, []string{root}) {
+				key, _ := filepath.Rel(root, g.Key)
+				gr := group{key: filepath.ToSlash(key), pm: g.PM, size: g.Size}
+				for _, f := range g.Folders {
+					rel, _ := filepath.Rel(root, f.Path)
+					gr.folders = append(gr.folders, filepath.ToSlash(rel))
+				}
+				got = append(got, gr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupFolders(%s) = %+v, want %+v", tt.by, got, tt.want)
+			}
+		})
+	}
+
+	byEcosystem := GroupFolders(folders, GroupByEcosystem, []string{root})
+	if len(byEcosystem) != 2 || byEcosystem[0].Key != "node" || byEcosystem[0].Size != 65 {
+		t.Errorf("GroupFolders(ecosystem) = %+v, want node (65 bytes) first, then rust", byEcosystem)
+	}
+}
@@ -0,0 +1,120 @@
+package pumu
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"pumu/internal/ignore"
+)
+
+// ignoreRules are the directories a walk never descends into. A rule is
+// either a bare name, such as .idea, matched anywhere in the tree,
+// or a path anchored to the home directory (~/Library), to an environment
+// variable (%APPDATA%, $XDG_CACHE_HOME/pip) or to the filesystem root.
+type ignoreRules struct {
+	names map[string]string // directory name -> rule
+	paths map[string]string // absolute directory -> rule
+}
+
+func newIgnoreRules(rules []string) ignoreRules {
+	r := ignoreRules{names: make(map[string]string), paths: make(map[string]string)}
+	for _, rule := range rules {
+		if !isPathRule(rule) {
+			r.names[rule] = rule
+			continue
+		}
+		// A rule naming an unset variable or an unknown home has no place to
+		// anchor to, so it matches nothing.
+		if dir, ok := expandRule(rule); ok {
+			r.paths[dir] = rule
+		}
+	}
+	return r
+}
+
+// matchName returns the bare-name rule that excludes directories called name.
+func (r ignoreRules) matchName(name string) (string, bool) {
+	rule, ok := r.names[name]
+	return rule, ok
+}
+
+// matchPath returns the anchored rule that excludes the directory at abs,
+// which must be absolute.
+func (r ignoreRules) matchPath(abs string) (string, bool) {
+	rule, ok := r.paths[abs]
+	return rule, ok
+}
+
+// isPathRule reports whether rule names a location rather than a bare name.
+func isPathRule(rule string) bool {
+	return strings.HasPrefix(rule, "~") || strings.HasPrefix(rule, "%") || strings.HasPrefix(rule, "$") ||
+		strings.ContainsRune(rule, '/') || strings.ContainsRune(rule, filepath.Separator)
+}
+
+// windowsVar matches a %NAME% environment variable reference.
+var windowsVar = regexp.MustCompile(`%[A-Za-z_][A-Za-z0-9_()]*%`)
+
+// expandRule returns the absolute directory a path rule names, expanding a
+// leading ~ and %NAME%, $NAME and ${NAME} variables. It reports false if the
+// home directory or a variable is unknown.
+func expandRule(rule string) (string, bool) {
+	path := rule
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		path = home + path[1:]
+	}
+
+	missing := false
+	lookup := func(name string) string {
+		value := os.Getenv(name)
+		if value == "" {
+			missing = true
+		}
+		return value
+	}
+	path = windowsVar.ReplaceAllStringFunc(path, func(ref string) string { return lookup(ref[1 : len(ref)-1]) })
+	path = os.Expand(path, lookup)
+	if missing {
+		return "", false
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	return abs, true
+}
+
+// skipRule returns the rule that keeps walks out of the directory at path,
+// whose absolute form absPath returns: a bare-name rule, an anchored rule
+// or a pattern of a .pumuignore file.
+func (s *Scanner) skipRule(path string, absPath func(string) string, ignores *ignore.Tree) (string, bool) {
+	if rule, ok := s.ignored.matchName(filepath.Base(path)); ok {
+		return rule, true
+	}
+	if rule, ok := s.ignored.matchPath(absPath(path)); ok {
+		return rule, true
+	}
+	return ignores.Rule(path, true)
+}
+
+// absPaths returns a function mapping the paths found while walking root,
+// which start with root as given, to absolute paths.
+func absPaths(root string) func(path string) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return filepath.Clean
+	}
+	return func(path string) string {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return path
+		}
+		return filepath.Join(absRoot, rel)
+	}
+}
@@ -0,0 +1,100 @@
+package pumu
+
+import (
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+)
+
+// mountPolicy decides whether a walk may enter a directory that lives on a
+// different filesystem than the scan roots. With oneFileSystem set, no mount
+// point is ever crossed. Otherwise local filesystems are entered, but network
+// and pseudo filesystems (NFS, SMB, sshfs and other FUSE mounts, /proc, ...)
+// are still skipped unless includeMounts is set. It is safe for concurrent use.
+type mountPolicy struct {
+	oneFileSystem bool
+	includeMounts bool
+
+	mu      sync.Mutex
+	devs    map[uint64]bool   // device -> may be entered
+	skipped map[string]string // mount point -> reason it was skipped
+}
+
+// newMountPolicy returns the policy for a scan of roots. The filesystems the
+// roots themselves live on are always allowed; roots that cannot be stat'ed
+// are left for the walk to report.
+func newMountPolicy(roots []string, oneFileSystem, includeMounts bool) *mountPolicy {
+	p := &mountPolicy{
+		oneFileSystem: oneFileSystem,
+		includeMounts: includeMounts,
+		devs:          make(map[uint64]bool),
+		skipped:       make(map[string]string),
+	}
+	for _, root := range roots {
+		if info, err := os.Stat(root); err == nil {
+			p.devs[statFile(info).id.dev] = true
+		}
+	}
+	return p
+}
+
+// active reports whether the policy can reject anything at all.
+func (p *mountPolicy) active() bool {
+	return p.oneFileSystem || !p.includeMounts
+}
+
+// allowsEntry is allows for a directory entry, stat'ing it only when needed.
+func (p *mountPolicy) allowsEntry(path string, d fs.DirEntry) bool {
+	if !p.active() {
+		return true
+	}
+	info, err := d.Info()
+	if err != nil {
+		return true
+	}
+	return p.allows(path, info)
+}
+
+// allows reports whether the directory at path may be entered.
+func (p *mountPolicy) allows(path string, info fs.FileInfo) bool {
+	if !p.active() {
+		return true
+	}
+	dev := statFile(info).id.dev
+
+	p.mu.Lock()
+	allowed, known := p.devs[dev]
+	p.mu.Unlock()
+	if known {
+		return allowed
+	}
+
+	reason := ""
+	if p.oneFileSystem {
+		reason = "different filesystem"
+	} else if fsType, skip := isRemoteOrVirtualFS(path); skip {
+		reason = fsType + " mount"
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.devs[dev] = reason == ""
+	if reason != "" {
+		p.skipped[path] = reason
+	}
+	return reason == ""
+}
+
+// skippedMounts returns the mount points the walk refused to enter, sorted by path.
+func (p *mountPolicy) skippedMounts() []SkippedMount {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	mounts := make([]SkippedMount, 0, len(p.skipped))
+	for path, reason := range p.skipped {
+		mounts = append(mounts, SkippedMount{Path: path, Reason: reason})
+	}
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].Path < mounts[j].Path })
+	return mounts
+}
@@ -0,0 +1,22 @@
+package pumu
+
+import "golang.org/x/sys/unix"
+
+// remoteOrVirtualFS lists the statfs type names of network, FUSE and pseudo
+// filesystems on macOS.
+var remoteOrVirtualFS = map[string]bool{
+	"nfs": true, "smbfs": true, "afpfs": true, "webdav": true, "ftp": true,
+	"cifs": true, "osxfuse": true, "macfuse": true, "fusefs": true,
+	"devfs": true, "autofs": true, "fdesc": true,
+}
+
+// isRemoteOrVirtualFS reports whether path is on a network or pseudo
+// filesystem, and which one.
+func isRemoteOrVirtualFS(path string) (string, bool) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return "", false
+	}
+	name := unix.ByteSliceToString(st.Fstypename[:])
+	return name, remoteOrVirtualFS[name]
+}
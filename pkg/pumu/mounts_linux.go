@@ -0,0 +1,45 @@
+package pumu
+
+import "golang.org/x/sys/unix"
+
+// remoteOrVirtualFS maps statfs magic numbers of network, FUSE and pseudo
+// filesystems to a short name for reporting.
+var remoteOrVirtualFS = map[uint32]string{
+	unix.NFS_SUPER_MAGIC:     "nfs",
+	unix.SMB_SUPER_MAGIC:     "smb",
+	unix.CIFS_SUPER_MAGIC:    "cifs",
+	unix.SMB2_SUPER_MAGIC:    "smb2",
+	unix.FUSE_SUPER_MAGIC:    "fuse",
+	unix.CODA_SUPER_MAGIC:    "coda",
+	unix.AFS_SUPER_MAGIC:     "afs",
+	unix.AFS_FS_MAGIC:        "afs",
+	unix.CEPH_SUPER_MAGIC:    "ceph",
+	unix.V9FS_MAGIC:          "9p",
+	unix.NCP_SUPER_MAGIC:     "ncp",
+	unix.AUTOFS_SUPER_MAGIC:  "autofs",
+	unix.PROC_SUPER_MAGIC:    "proc",
+	unix.SYSFS_MAGIC:         "sysfs",
+	unix.DEVPTS_SUPER_MAGIC:  "devpts",
+	unix.CGROUP_SUPER_MAGIC:  "cgroup",
+	unix.CGROUP2_SUPER_MAGIC: "cgroup2",
+	unix.DEBUGFS_MAGIC:       "debugfs",
+	unix.TRACEFS_MAGIC:       "tracefs",
+	unix.SECURITYFS_MAGIC:    "securityfs",
+	unix.BPF_FS_MAGIC:        "bpf",
+	unix.PSTOREFS_MAGIC:      "pstore",
+	unix.HUGETLBFS_MAGIC:     "hugetlbfs",
+	unix.EFIVARFS_MAGIC:      "efivarfs",
+	unix.BINFMTFS_MAGIC:      "binfmt_misc",
+	unix.NSFS_MAGIC:          "nsfs",
+}
+
+// isRemoteOrVirtualFS reports whether path is on a network or pseudo
+// filesystem, and which one.
+func isRemoteOrVirtualFS(path string) (string, bool) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return "", false
+	}
+	name, ok := remoteOrVirtualFS[uint32(st.Type)] //nolint:gosec // magic numbers fit in 32 bits
+	return name, ok
+}
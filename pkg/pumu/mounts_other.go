@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package pumu
+
+// isRemoteOrVirtualFS cannot tell filesystem types apart on this platform,
+// so only --one-file-system applies.
+func isRemoteOrVirtualFS(string) (string, bool) {
+	return "", false
+}
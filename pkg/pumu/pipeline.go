@@ -0,0 +1,238 @@
+package pumu
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"pumu/internal/cache"
+	"pumu/internal/ignore"
+	"pumu/internal/pkg"
+)
+
+// The scan pipeline is discover -> size -> analyze. Each stage is a pool of
+// workers reading from the previous stage's channel, so the first results
+// reach the user while the walk is still running.
+
+// discovery streams the targets found by a background walk of the roots.
+// Drain folders until it is closed before reading unverified or err.
+type discovery struct {
+	folders    chan TargetFolder
+	mounts     *mountPolicy
+	unverified []string // held-back unverified targets, sorted
+	skipped    []SkippedDir
+	filtered   atomic.Int64
+	err        error
+}
+
+// discoverTargets starts walking roots, one after the other, and returns
+// immediately. Unverified targets are held back unless IncludeUnverified is
+// set, and targets failing the filters that need no size are counted and
+// dropped. Aggregated targets such as __pycache__ are grouped by project and
+// passed on once the walk of their root is over. A TargetFound event is sent
+// for each target passed on. A root that cannot be walked does not stop the
+// others; canceling ctx stops the walk and closes folders early.
+func (s *Scanner) discoverTargets(ctx context.Context, roots []string) *discovery {
+	d := &discovery{
+		folders: make(chan TargetFolder),
+		mounts:  newMountPolicy(roots, s.opts.OneFileSystem, s.opts.IncludeMounts),
+	}
+
+	go func() {
+		defer close(d.folders)
+		pass := func(f TargetFolder) {
+			if !s.opts.Filter.matchesFound(f) {
+				d.filtered.Add(1)
+				return
+			}
+			s.emit(Event{Kind: TargetFound, Folder: f})
+			d.folders <- f
+		}
+
+		var mu sync.Mutex
+		var errs []error
+		for _, root := range roots {
+			if ctx.Err() != nil {
+				break
+			}
+			scattered := newAggregator(root)
+			var skipped func(path, rule string)
+			if s.opts.ExplainSkips {
+				skipped = func(path, rule string) {
+					mu.Lock()
+					d.skipped = append(d.skipped, SkippedDir{Path: path, Rule: rule})
+					mu.Unlock()
+				}
+			}
+			err := s.walkTargets(ctx, root, d.mounts, skipped, func(path, ecosystem string, verified bool) {
+				switch {
+				case !verified && !s.opts.IncludeUnverified:
+					mu.Lock()
+					d.unverified = append(d.unverified, path)
+					mu.Unlock()
+				case isAggregated(filepath.Base(path)):
+					scattered.add(path, ecosystem)
+				default:
+					pass(TargetFolder{Path: path, Ecosystem: ecosystem})
+				}
+			})
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for _, f := range scattered.folders() {
+				pass(f)
+			}
+		}
+		d.err = errors.Join(errs...)
+		sort.Strings(d.unverified)
+		sort.Slice(d.skipped, func(i, j int) bool { return d.skipped[i].Path < d.skipped[j].Path })
+	}()
+
+	return d
+}
+
+// report describes what the walk of roots held back. Call it only once
+// the folders have been sized.
+func (d *discovery) report(roots []string) Report {
+	return Report{
+		Roots:         roots,
+		Unverified:    d.unverified,
+		SkippedMounts: d.mounts.skippedMounts(),
+		SkippedDirs:   d.skipped,
+		Filtered:      int(d.filtered.Load()),
+	}
+}
+
+// walkTargets walks root and calls found, possibly concurrently, for every
+// deletable target with its ecosystem, saying whether its parent directory
+// backs it with evidence. Any other directory whose contents identify it as
+// a target, such as a virtual environment by its pyvenv.cfg or a cache by
+// its CACHEDIR.TAG, is found too. Unless skipped is nil, it is
+// called with every directory an ignore rule keeps the walk out of. Once ctx
+// is canceled no more directories are entered.
+func (s *Scanner) walkTargets(ctx context.Context, root string, mounts *mountPolicy, skipped func(path, rule string), found func(path, ecosystem string, verified bool)) error {
+	ignores := ignore.NewTree(root)
+	absPath := absPaths(root)
+
+	return walkDirs(root, s.workers, func(path string, d fs.DirEntry) bool {
+		if ctx.Err() != nil || !d.IsDir() {
+			return false
+		}
+		if d.Name() == ".git" {
+			return false
+		}
+		if rule, ok := s.skipRule(path, absPath, ignores); ok {
+			if skipped != nil {
+				skipped(path, rule)
+			}
+			return false
+		}
+		if !mounts.allowsEntry(path, d) {
+			return false
+		}
+		if !s.isDeletableTarget(d.Name()) || !isAnchored(path) {
+			return true
+		}
+
+		ecosystem, verified := classifyTarget(path)
+		found(path, ecosystem, verified)
+		return false
+	}, func(dir string, entries []fs.DirEntry) bool {
+		// signedTarget refuses root and project directories, so a stray
+		// CACHEDIR.TAG or state file cannot turn them into targets.
+		if ecosystem, ok := s.signedTarget(root, dir, entries); ok {
+			found(dir, ecosystem, true)
+			return false
+		}
+		return true
+	})
+}
+
+// sizeFolders measures each folder found and sends it on as soon as its
+// size is known, unless it is smaller than the filter's MinSize. The returned
+// channel is closed once found.folders is drained. Hardlinks are tracked
+// across every folder in the stream, and the discovery's mount policy
+// decides which mount points inside a folder are measured. Unless index is
+// nil, sizes of unchanged folders are taken from it and the index is saved
+// before the channel is closed. A FolderSized event is sent for each folder
+// passed on. Once ctx is canceled, the remaining folders are drained without
+// being measured.
+func (s *Scanner) sizeFolders(ctx context.Context, found *discovery, index *cache.Index) <-chan TargetFolder {
+	out := make(chan TargetFolder)
+	links := newLinkTracker()
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range found.folders {
+				if ctx.Err() != nil {
+					continue
+				}
+				usage := measureTarget(f, links, found.mounts, index)
+				f.Size, f.Apparent = usage.Reclaimable, usage.Apparent
+				if !s.opts.Filter.matchesSized(f) {
+					found.filtered.Add(1)
+					continue
+				}
+				s.emit(Event{Kind: FolderSized, Folder: f})
+				out <- f
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		if index != nil {
+			// The cache only saves time; failing to write it must not fail the scan.
+			_ = index.Save()
+		}
+		close(out)
+	}()
+
+	return out
+}
+
+// analyzeFolders runs AnalyzeFolder on each incoming folder, looking its
+// workspace up with workspaces, and sends the result on as soon as it is
+// ready. The returned channel is closed once folders is drained; once ctx is
+// canceled, the remaining folders are drained without being analyzed. A
+// FolderAnalyzed event is sent for each result.
+func (s *Scanner) analyzeFolders(ctx context.Context, folders <-chan TargetFolder, workspaces *workspaceFinder) <-chan PruneResult {
+	out := make(chan PruneResult)
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range folders {
+				if ctx.Err() != nil {
+					continue
+				}
+				// projectOfFolder picks the manager of f's ecosystem, such as
+				// Composer for a Laravel app's vendor, not npm.
+				project, pm := projectDirOf(f.Path), pkg.Unknown
+				if p, ok := projectOfFolder(f, workspaces); ok {
+					project, pm = p.Dir, p.PM
+				}
+				analysis := pkg.AnalyzeFolder(ctx, f.Path, project, pm, f.Size)
+				result := PruneResult{TargetFolder: f, Score: analysis.Score, Reason: analysis.Reason}
+				s.emit(Event{Kind: FolderAnalyzed, Folder: f, Result: result})
+				out <- result
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
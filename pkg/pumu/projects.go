@@ -0,0 +1,264 @@
+package pumu
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"pumu/internal/ignore"
+	"pumu/internal/pkg"
+)
+
+// Project is a directory whose dependencies are managed by a known package manager.
+type Project struct {
+	Dir string
+	PM  PackageManager
+	// Workspace is set when Dir is a monorepo root (a pnpm, npm or yarn
+	// workspace, or a Cargo workspace) that installs its members' dependencies.
+	Workspace bool
+}
+
+// DetectProject reports whether dir is a project, detecting its package
+// manager from its workspace declaration, lockfiles and manifests.
+func DetectProject(dir string) (Project, bool) {
+	if ws, ok := pkg.ReadWorkspace(dir); ok {
+		return Project{Dir: dir, PM: ws.PM, Workspace: true}, true
+	}
+	pm := pkg.DetectManager(dir)
+	return Project{Dir: dir, PM: pm}, pm != pkg.Unknown
+}
+
+// ProjectOf returns the project whose dependencies dir's are installed by:
+// the workspace root if dir is a workspace member, otherwise dir itself if
+// it is a project.
+func ProjectOf(dir string) (Project, bool) {
+	if ws, ok := pkg.FindWorkspace(dir); ok {
+		return Project{Dir: ws.Root, PM: ws.PM, Workspace: true}, true
+	}
+	return DetectProject(dir)
+}
+
+// projectOfFolder returns the project that installs the folder f, like
+// ProjectOf does for its directory, looking its workspace up with
+// workspaces. When that directory has lockfiles of several ecosystems, such
+// as a Laravel app with composer.lock and package-lock.json, the package
+// manager of f's ecosystem is chosen, so that vendor is reinstalled by
+// Composer and node_modules by npm.
+func projectOfFolder(f TargetFolder, workspaces *workspaceFinder) (Project, bool) {
+	p, ok := workspaces.projectOf(projectDirOf(f.Path))
+	if !ok || p.Workspace || f.Ecosystem == "" || p.Ecosystem() == f.Ecosystem {
+		return p, ok
+	}
+	for _, pm := range pkg.DetectManagers(p.Dir) {
+		if managerEcosystems[pm] == f.Ecosystem {
+			p.PM = pm
+			break
+		}
+	}
+	return p, true
+}
+
+// managerEcosystems maps each package manager to its ecosystem.
+var managerEcosystems = map[PackageManager]string{
+	pkg.Npm:      "node",
+	pkg.Pnpm:     "node",
+	pkg.Yarn:     "node",
+	pkg.Bun:      "node",
+	pkg.Deno:     "deno",
+	pkg.Cargo:    "rust",
+	pkg.Go:       "go",
+	pkg.Pip:      "python",
+	pkg.Maven:    "java",
+	pkg.Gradle:   "java",
+	pkg.Composer: "php",
+	pkg.Bundler:  "ruby",
+	pkg.Uv:       "python",
+	pkg.Poetry:   "python",
+	pkg.Pipenv:   "python",
+	pkg.Pdm:      "python",
+	pkg.Conda:    "python",
+	pkg.Dotnet:   "dotnet",
+	pkg.Mix:      "elixir",
+	pkg.Pub:      "dart",
+	pkg.Stack:    "haskell",
+	pkg.Cabal:    "haskell",
+}
+
+// Ecosystem returns the ecosystem of the project's package manager, e.g. "node".
+func (p Project) Ecosystem() string {
+	return managerEcosystems[p.PM]
+}
+
+// DependencyDir returns the folder the project's dependencies are installed
+// in, or "" if they are installed outside the project. Maven and Gradle keep
+// dependencies in a shared cache outside the project, so for them it is the
+// build output instead.
+func (p Project) DependencyDir() string {
+	switch p.PM {
+	case pkg.Cargo, pkg.Maven:
+		return filepath.Join(p.Dir, "target")
+	case pkg.Gradle:
+		return filepath.Join(p.Dir, "build")
+	case pkg.Composer:
+		return filepath.Join(p.Dir, "vendor")
+	case pkg.Dotnet:
+		return filepath.Join(p.Dir, "obj")
+	case pkg.Mix:
+		return filepath.Join(p.Dir, "deps")
+	case pkg.Pub:
+		return filepath.Join(p.Dir, ".dart_tool")
+	case pkg.Stack:
+		return filepath.Join(p.Dir, ".stack-work")
+	case pkg.Cabal:
+		return filepath.Join(p.Dir, "dist-newstyle")
+	case pkg.Bundler:
+		return filepath.Join(p.Dir, "vendor", "bundle")
+	case pkg.Pip, pkg.Uv, pkg.Poetry, pkg.Pipenv, pkg.Pdm:
+		// The venv the health check and install use, such as env or venv,
+		// or the .venv install would create.
+		if venv := pkg.LocalVenv(p.Dir); venv != "" {
+			return filepath.Join(p.Dir, venv)
+		}
+		return filepath.Join(p.Dir, ".venv")
+	case pkg.Conda:
+		// Conda environments live outside the project, and `conda env
+		// update` never re-creates a .venv next to environment.yml.
+		return ""
+	default:
+		return filepath.Join(p.Dir, "node_modules")
+	}
+}
+
+// ProjectsOf returns the projects that folders belong to, once each and in
+// the order first seen. A directory with folders of several ecosystems is
+// returned once per package manager. Folders of workspace members belong to
+// the workspace root, so that it is installed once; folders whose parent is
+// not a known project are left out.
+func ProjectsOf(folders []TargetFolder) []Project {
+	seen := make(map[Project]bool)
+	var projects []Project
+
+	for _, folder := range folders {
+		p, ok := projectOfFolder(folder, nil)
+		if !ok || seen[p] {
+			continue
+		}
+		seen[p] = true
+		projects = append(projects, p)
+	}
+	return projects
+}
+
+// FindProjects recursively scans roots for directories containing
+// lockfiles/manifests. Workspace members are left out, since their
+// workspace root stands for them; only workspaces declared under roots
+// count. The result is sorted by directory. Once ctx is canceled no more
+// directories are entered and ctx.Err() is returned.
+func (s *Scanner) FindProjects(ctx context.Context, roots []string) ([]Project, Report, error) {
+	roots = NormalizeRoots(roots)
+	mounts := newMountPolicy(roots, s.opts.OneFileSystem, s.opts.IncludeMounts)
+
+	var projects []Project
+	var mu sync.Mutex
+	var errs []error
+
+	workspaces := newWorkspaceFinder(roots)
+	for _, root := range roots {
+		ignores := ignore.NewTree(root)
+		absPath := absPaths(root)
+		err := walkDirs(root, s.workers, func(path string, d fs.DirEntry) bool {
+			if ctx.Err() != nil || !d.IsDir() {
+				return false
+			}
+			if d.Name() == ".git" || (s.isDeletableTarget(d.Name()) && isAnchored(path)) || !mounts.allowsEntry(path, d) {
+				return false
+			}
+			if _, ok := s.skipRule(path, absPath, ignores); ok {
+				return false
+			}
+
+			if p, ok := DetectProject(path); ok && !workspaces.isMember(path) {
+				mu.Lock()
+				projects = append(projects, p)
+				mu.Unlock()
+			}
+			return true
+		}, func(dir string, entries []fs.DirEntry) bool {
+			_, signed := s.signedTarget(root, dir, entries)
+			return !signed
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Dir < projects[j].Dir
+	})
+	report := Report{Roots: roots, SkippedMounts: mounts.skippedMounts()}
+	return projects, report, firstErr(ctx.Err(), errors.Join(errs...))
+}
+
+// Check runs CheckHealth on each project in turn. If ctx is canceled it stops
+// and returns the results so far with ctx.Err().
+func (s *Scanner) Check(ctx context.Context, projects []Project) ([]HealthResult, error) {
+	results := make([]HealthResult, 0, len(projects))
+	for _, p := range projects {
+		result := s.CheckHealth(ctx, p)
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// CheckHealth verifies the integrity of the project's installed dependencies
+// using its package manager and sends a HealthChecked event. Canceling ctx
+// stops the check, and no event is sent.
+func (s *Scanner) CheckHealth(ctx context.Context, p Project) HealthResult {
+	result := pkg.CheckHealth(ctx, p.Dir, p.PM)
+	if ctx.Err() == nil {
+		s.emit(Event{Kind: HealthChecked, Project: p, Health: result})
+	}
+	return result
+}
+
+// RemoveDependencies deletes the project's dependency folder, reporting
+// whether there was one to delete. Projects whose dependencies are installed
+// outside them have none. Deleting it sends DeleteStarted and then
+// DeleteFinished or DeleteFailed events.
+func (s *Scanner) RemoveDependencies(p Project) (removed bool, err error) {
+	dir := p.DependencyDir()
+	if dir == "" || !pkg.DirExists(dir) {
+		return false, nil
+	}
+
+	f := TargetFolder{Path: dir, Ecosystem: p.Ecosystem()}
+	s.emit(Event{Kind: DeleteStarted, Folder: f})
+	_, err = pkg.RemoveDirectory(dir)
+	s.emit(deleteEvent(f, err))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Install runs the project's package manager to install its dependencies,
+// sending a ReinstallStarted event and then a ReinstallFinished or
+// ReinstallFailed one. The command's output goes to out, or is discarded if
+// out is nil. Canceling ctx interrupts the command.
+func (s *Scanner) Install(ctx context.Context, p Project, out io.Writer) error {
+	s.emit(Event{Kind: ReinstallStarted, Project: p})
+	err := pkg.InstallDependencies(ctx, p.Dir, p.PM, out)
+	if err != nil {
+		s.emit(Event{Kind: ReinstallFailed, Project: p, Err: err})
+	} else {
+		s.emit(Event{Kind: ReinstallFinished, Project: p})
+	}
+	return err
+}
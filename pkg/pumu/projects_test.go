@@ -0,0 +1,204 @@
+package pumu
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// workspaceTree creates a pnpm workspace and a Cargo workspace under a
+// temporary root and returns it.
+func workspaceTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	mkdirs(t, root,
+		"web/node_modules", "web/packages/ui/node_modules", "web/apps/site/node_modules",
+		"rs/target", "rs/crates/cli", "rs/crates/core",
+		"solo/node_modules",
+	)
+	writeFile(t, root, "web/pnpm-workspace.yaml", "packages:\n  - packages/*\n  - apps/*\n")
+	writeFile(t, root, "web/package.json", "{}")
+	writeFile(t, root, "web/packages/ui/package.json", "{}")
+	writeFile(t, root, "web/apps/site/package.json", "{}")
+	writeFile(t, root, "rs/Cargo.toml", "[workspace]\nmembers = [\"crates/*\"]\n")
+	writeFile(t, root, "rs/crates/cli/Cargo.toml", "[package]\nname = \"cli\"\n")
+	writeFile(t, root, "rs/crates/core/Cargo.toml", "[package]\nname = \"core\"\n")
+	writeFile(t, root, "solo/package-lock.json", "{}")
+	return root
+}
+
+func TestProjectsOfInstallsWorkspacesOnce(t *testing.T) {
+	root := workspaceTree(t)
+	var folders []TargetFolder
+	for _, rel := range []string{
+		"web/packages/ui/node_modules", "web/node_modules", "web/apps/site/node_modules",
+		"rs/target", "solo/node_modules",
+	} {
+		folders = append(folders, TargetFolder{Path: filepath.Join(root, filepath.FromSlash(rel))})
+	}
+
+	want := []Project{
+		{Dir: filepath.Join(root, "web"), PM: "pnpm", Workspace: true},
+		{Dir: filepath.Join(root, "rs"), PM: "cargo", Workspace: true},
+		{Dir: filepath.Join(root, "solo"), PM: "npm"},
+	}
+	if got := ProjectsOf(folders); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProjectsOf() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindProjectsSkipsWorkspaceMembers(t *testing.T) {
+	root := workspaceTree(t)
+
+	projects, _, err := New(Options{}).FindProjects(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("FindProjects() error = %v", err)
+	}
+
+	var got []string
+	for _, p := range projects {
+		rel, _ := filepath.Rel(root, p.Dir)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	if want := []string{"rs", "solo", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindProjects() = %v, want %v", got, want)
+	}
+}
+
+func TestGroupByProjectUsesWorkspaceRoot(t *testing.T) {
+	root := workspaceTree(t)
+	member := TargetFolder{Path: filepath.Join(root, "web", "packages", "ui", "node_modules")}
+
+	key, pm := GroupKey(member, GroupByProject, []string{root})
+	if key != filepath.Join(root, "web") || pm != "pnpm" {
+		t.Errorf("GroupKey(member) = %s, %s, want the workspace root with pnpm", key, pm)
+	}
+}
+
+func TestRemoveDependenciesLeavesCondaVenv(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, ".venv/lib")
+	writeFile(t, root, "environment.yml", "name: ml\n")
+
+	p, ok := DetectProject(root)
+	if !ok || p.PM != Conda {
+		t.Fatalf("DetectProject() = %+v, %v, want a conda project", p, ok)
+	}
+	// Repair removes the dependency folder before reinstalling; conda has
+	// none in the project, so .venv must survive.
+	removed, err := New(Options{}).RemoveDependencies(p)
+	if err != nil || removed {
+		t.Errorf("RemoveDependencies() = %v, %v, want false, nil", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".venv")); err != nil {
+		t.Errorf(".venv was removed: %v", err)
+	}
+}
+
+func TestFindProjectsStopsAtScanRoot(t *testing.T) {
+	root := workspaceTree(t)
+	packages := filepath.Join(root, "web", "packages")
+	writeFile(t, root, "web/packages/ui/package-lock.json", "{}")
+
+	// Scanned on its own, a member is no member: its workspace is declared
+	// above the scan.
+	projects, _, err := New(Options{}).FindProjects(context.Background(), []string{packages})
+	if err != nil {
+		t.Fatalf("FindProjects() error = %v", err)
+	}
+	want := []Project{{Dir: filepath.Join(packages, "ui"), PM: "npm"}}
+	if !reflect.DeepEqual(projects, want) {
+		t.Errorf("FindProjects() = %+v, want %+v", projects, want)
+	}
+}
+
+func TestWorkspaceFinderMemoizesUpToRoot(t *testing.T) {
+	root := workspaceTree(t)
+	w := newWorkspaceFinder([]string{root})
+
+	for _, rel := range []string{"web/packages/ui", "web/apps/site", "web"} {
+		ws, ok := w.find(filepath.Join(root, filepath.FromSlash(rel)))
+		if !ok || ws.Root != filepath.Join(root, "web") {
+			t.Errorf("find(%s) = %+v, %v, want the web workspace", rel, ws, ok)
+		}
+	}
+	if _, ok := w.find(filepath.Join(root, "solo")); ok {
+		t.Errorf("find(solo) found a workspace, want none")
+	}
+
+	var read []string
+	for dir := range w.declared {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			t.Errorf("read %s, above the root", dir)
+		}
+		read = append(read, filepath.ToSlash(rel))
+	}
+	sort.Strings(read)
+	if want := []string{".", "solo", "web", "web/apps", "web/apps/site", "web/packages", "web/packages/ui"}; !reflect.DeepEqual(read, want) {
+		t.Errorf("read %v, want %v", read, want)
+	}
+}
+
+func TestRepairReplacesBrokenVenvOfAnyName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs POSIX shell scripts as interpreters")
+	}
+	root := t.TempDir()
+	mkdirs(t, root, "env/bin", "bin")
+	writeFile(t, root, "requirements.txt", "")
+	writeFile(t, root, "env/pyvenv.cfg", "home = /usr/bin\n")
+	// A venv whose Python was removed, and a python3 that creates a working one.
+	writeScript(t, filepath.Join(root, "env", "bin", "python"), "#!/bin/sh\nexit 1\n")
+	writeScript(t, filepath.Join(root, "bin", "python3"),
+		"#!/bin/sh\nmkdir -p \"$3/bin\" && printf '#!/bin/sh\\nexit 0\\n' > \"$3/bin/python\" && chmod +x \"$3/bin/python\"\n")
+	t.Setenv("PATH", filepath.Join(root, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx := context.Background()
+	s := New(Options{})
+	p := Project{Dir: root, PM: Pip}
+	if s.CheckHealth(ctx, p).Healthy {
+		t.Fatal("CheckHealth() = healthy, want the broken env unhealthy")
+	}
+
+	// Repair: remove the broken venv, whatever it is called, and reinstall.
+	if removed, err := s.RemoveDependencies(p); err != nil || !removed {
+		t.Fatalf("RemoveDependencies() = %v, %v, want true, nil", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "env")); !os.IsNotExist(err) {
+		t.Errorf("env still exists after RemoveDependencies()")
+	}
+	if err := s.Install(ctx, p, nil); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if result := s.CheckHealth(ctx, p); !result.Healthy {
+		t.Errorf("CheckHealth() after repair = %v, want healthy", result.Issues)
+	}
+}
+
+func writeScript(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o700); err != nil { //nolint:gosec // test script must be executable
+		t.Fatal(err)
+	}
+}
+
+func TestGroupByProjectStopsAtScanRoot(t *testing.T) {
+	root := workspaceTree(t)
+	packages := filepath.Join(root, "web", "packages")
+	member := TargetFolder{Path: filepath.Join(packages, "ui", "node_modules")}
+
+	// The workspace is declared above the scan, so the member is its own group.
+	groups := GroupFolders([]TargetFolder{member}, GroupByProject, []string{packages})
+	if len(groups) != 1 || groups[0].Key != filepath.Join(packages, "ui") || groups[0].PM != "" {
+		t.Errorf("GroupFolders() = %+v, want the member's own directory", groups)
+	}
+	if key, _ := GroupKey(member, GroupByProject, []string{packages}); key != filepath.Join(packages, "ui") {
+		t.Errorf("GroupKey(member) = %s, want %s", key, filepath.Join(packages, "ui"))
+	}
+}
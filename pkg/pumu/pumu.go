@@ -0,0 +1,334 @@
+// Package pumu finds, measures, analyzes and removes heavy dependency and
+// build folders (node_modules, target, .venv, ...) across many projects.
+//
+// A Scanner walks one or more roots and returns typed results; it never
+// prints anything. Progress is reported through Options.OnEvent, which lets
+// callers render results while a scan is still running:
+//
+//	s := pumu.New(pumu.Options{OneFileSystem: true})
+//	folders, report, err := s.Scan(ctx, []string{"~/work"})
+//	...
+//	res, err := s.Delete(ctx, folders)
+package pumu
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"pumu/internal/pkg"
+)
+
+// DefaultConcurrency is the number of concurrent walk, size, analysis and
+// delete operations used when Options.Concurrency is not set.
+const DefaultConcurrency = 20
+
+// PackageManager identifies the tool that owns a project, such as "npm" or "cargo".
+type PackageManager = pkg.PackageManager
+
+// Supported package managers, to compare Project.PM and Group.PM against.
+const (
+	Npm      = pkg.Npm
+	Pnpm     = pkg.Pnpm
+	Yarn     = pkg.Yarn
+	Bun      = pkg.Bun
+	Deno     = pkg.Deno
+	Cargo    = pkg.Cargo
+	Go       = pkg.Go
+	Pip      = pkg.Pip
+	Maven    = pkg.Maven
+	Gradle   = pkg.Gradle
+	Composer = pkg.Composer
+	Bundler  = pkg.Bundler
+	Uv       = pkg.Uv
+	Poetry   = pkg.Poetry
+	Pipenv   = pkg.Pipenv
+	Pdm      = pkg.Pdm
+	Conda    = pkg.Conda
+	Dotnet   = pkg.Dotnet
+	Mix      = pkg.Mix
+	Pub      = pkg.Pub
+	Stack    = pkg.Stack
+	Cabal    = pkg.Cabal
+	// Unknown is the package manager of a directory pumu detects none in.
+	Unknown = pkg.Unknown
+)
+
+// HealthResult is the outcome of checking a project's installed dependencies.
+type HealthResult = pkg.HealthResult
+
+// TargetFolder holds the path and calculated size of a detected heavy dependency folder.
+type TargetFolder struct {
+	Path      string
+	Ecosystem string // e.g. "node", "rust" or "python"; "" if unknown
+	Size      int64  // reclaimable bytes on disk, deduplicated across hardlinks
+	Apparent  int64  // sum of file sizes, counting every hardlink
+	// Members are the folders an aggregate stands for, such as every
+	// __pycache__ in a project, whose Path is then <project>/**/__pycache__.
+	// Nil for an ordinary folder.
+	Members []string
+}
+
+// Paths returns the folders f stands for: its members if it is an
+// aggregate, or else its path.
+func (f TargetFolder) Paths() []string {
+	if f.Members != nil {
+		return f.Members
+	}
+	return []string{f.Path}
+}
+
+// PruneResult is the staleness analysis of one folder.
+type PruneResult struct {
+	TargetFolder
+	Score  int    // 0-100; the higher, the safer the folder is to delete
+	Reason string // human-readable explanation of the score
+}
+
+// Options configures a Scanner. The zero value scans with the default
+// targets and ignored directories, crosses into other local filesystems and
+// caches sizes on disk.
+type Options struct {
+	// Concurrency bounds the number of concurrent walk, size, analysis and
+	// delete operations. Zero means DefaultConcurrency.
+	Concurrency int
+	// Targets are the folder names, or glob patterns such as "*.egg-info",
+	// treated as deletable. Nil means DefaultTargets(). Listing ".venv" also
+	// makes any directory with a pyvenv.cfg a target, whatever its name, as
+	// listing "node_modules" does any with npm's, pnpm's or yarn's state
+	// file, and listing "CACHEDIR.TAG" any directory tagged as a cache.
+	Targets []string
+	// IgnoredDirs are the directories never descended into: bare names,
+	// matched anywhere, or paths anchored to the home directory (~/Library),
+	// an environment variable (%APPDATA%) or the filesystem root. Nil means
+	// DefaultIgnoredDirs().
+	IgnoredDirs []string
+	// ExplainSkips records in Report.SkippedDirs every directory the walk
+	// stayed out of because of an ignore rule, and the rule.
+	ExplainSkips bool
+	// IncludeUnverified also handles targets whose parent directory
+	// shows no evidence of the tool that owns them.
+	IncludeUnverified bool
+	// OneFileSystem stops walks at mount points, like `du -x`.
+	OneFileSystem bool
+	// IncludeMounts lets walks enter network and pseudo filesystems.
+	IncludeMounts bool
+	// NoCache measures every folder instead of reusing sizes from the index.
+	NoCache bool
+	// Filter narrows the folders Scan and Analyze return.
+	Filter Filter
+	// OnEvent, if set, is called as results become available. Calls are
+	// serialized, so it need not be safe for concurrent use, but a slow
+	// callback slows the scan down.
+	OnEvent func(Event)
+}
+
+// Report describes what a scan left out on purpose.
+type Report struct {
+	// Roots are the roots actually walked, after dropping repeated roots
+	// and roots nested inside others.
+	Roots []string
+	// Unverified are the targets skipped because their parent directory
+	// shows no evidence of the tool that owns them, sorted.
+	Unverified []string
+	// SkippedMounts are the mount points the walk did not cross, sorted by path.
+	SkippedMounts []SkippedMount
+	// SkippedDirs are the directories ignore rules kept the walk out of,
+	// sorted by path. Only filled in if Options.ExplainSkips is set.
+	SkippedDirs []SkippedDir
+	// Filtered is the number of folders left out by Options.Filter.
+	Filtered int
+}
+
+// SkippedMount is a mount point a walk refused to enter.
+type SkippedMount struct {
+	Path   string
+	Reason string // e.g. "different filesystem" or "nfs mount"
+}
+
+// SkippedDir is a directory an ignore rule kept a walk out of.
+type SkippedDir struct {
+	Path string
+	// Rule is the rule as configured, such as "~/Library" or ".idea", or
+	// "<ignore file>: <pattern>" for a .pumuignore pattern.
+	Rule string
+}
+
+// DeleteResult is the outcome of Delete.
+type DeleteResult struct {
+	Removed []TargetFolder
+	Failed  []TargetFolder
+	Freed   int64 // reclaimable bytes of the removed folders
+}
+
+// Scanner finds and handles dependency folders. It is safe for concurrent use.
+type Scanner struct {
+	opts           Options
+	workers        int
+	targets        map[string]bool
+	targetPatterns []string // the entries of targets that are glob patterns
+	ignored        ignoreRules
+	signatures     map[string]pkg.Target // signature file -> the target it identifies, if a target
+	cacheTags      bool                  // whether directories with a CACHEDIR.TAG are targets
+
+	emitMu sync.Mutex
+}
+
+// New returns a Scanner configured by opts.
+func New(opts Options) *Scanner {
+	s := &Scanner{
+		opts:    opts,
+		workers: opts.Concurrency,
+		targets: nameSet(opts.Targets, DefaultTargets()),
+	}
+	ignored := opts.IgnoredDirs
+	if ignored == nil {
+		ignored = DefaultIgnoredDirs()
+	}
+	s.ignored = newIgnoreRules(ignored)
+	if s.workers <= 0 {
+		s.workers = DefaultConcurrency
+	}
+	// CACHEDIR.TAG in the targets switches tagged caches on; it is a file,
+	// never the name of a folder to delete.
+	s.cacheTags = s.targets[pkg.CacheTag]
+	delete(s.targets, pkg.CacheTag)
+	for name := range s.targets {
+		if strings.ContainsAny(name, "*?[") {
+			s.targetPatterns = append(s.targetPatterns, name)
+		}
+	}
+	s.signatures = make(map[string]pkg.Target)
+	for _, t := range pkg.Targets {
+		for _, name := range t.Signatures {
+			if s.targets[t.Name] {
+				s.signatures[name] = t
+			}
+		}
+	}
+	return s
+}
+
+func nameSet(names, defaults []string) map[string]bool {
+	if names == nil {
+		names = defaults
+	}
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// Scan walks roots and measures every deletable folder found that passes
+// Options.Filter. Overlapping roots are walked once. A TargetFound event is
+// sent when each folder is found and a FolderSized event as soon as its size
+// is known; the returned folders are sorted by path.
+//
+// A root that cannot be walked does not stop the others; the error is
+// returned along with everything found elsewhere. If ctx is canceled the
+// scan stops early and ctx.Err() is returned with the partial results.
+func (s *Scanner) Scan(ctx context.Context, roots []string) ([]TargetFolder, Report, error) {
+	roots = NormalizeRoots(roots)
+	found := s.discoverTargets(ctx, roots)
+
+	var folders []TargetFolder
+	for f := range s.sizeFolders(ctx, found, s.openSizeIndex()) {
+		folders = append(folders, f)
+	}
+
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Path < folders[j].Path })
+	return folders, found.report(roots), firstErr(ctx.Err(), found.err)
+}
+
+// Analyze is Scan followed by a staleness analysis of every folder. A
+// FolderAnalyzed event is sent as soon as each folder is scored; the returned
+// results are sorted by path.
+func (s *Scanner) Analyze(ctx context.Context, roots []string) ([]PruneResult, Report, error) {
+	roots = NormalizeRoots(roots)
+	found := s.discoverTargets(ctx, roots)
+	sized := s.sizeFolders(ctx, found, s.openSizeIndex())
+
+	var results []PruneResult
+	for r := range s.analyzeFolders(ctx, sized, newWorkspaceFinder(roots)) {
+		results = append(results, r)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results, found.report(roots), firstErr(ctx.Err(), found.err)
+}
+
+// Delete removes folders concurrently, sending a DeleteStarted event for each
+// and then a DeleteFinished or DeleteFailed one.
+// Once ctx is canceled no new deletion is started, but those under way are
+// waited for, so that an interrupt never leaves a folder half-deleted by a
+// goroutine killed mid-way; ctx.Err() is then returned along with what was
+// actually removed.
+func (s *Scanner) Delete(ctx context.Context, folders []TargetFolder) (DeleteResult, error) {
+	var res DeleteResult
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, s.workers)
+	for _, folder := range folders {
+		if !acquire(ctx, sem) {
+			break
+		}
+		wg.Add(1)
+		go func(f TargetFolder) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			s.emit(Event{Kind: DeleteStarted, Folder: f})
+			err := removeFolder(f)
+			mu.Lock()
+			if err == nil {
+				res.Removed = append(res.Removed, f)
+				res.Freed += f.Size
+			} else {
+				res.Failed = append(res.Failed, f)
+			}
+			mu.Unlock()
+			s.emit(deleteEvent(f, err))
+		}(folder)
+	}
+	wg.Wait()
+
+	return res, ctx.Err()
+}
+
+// removeFolder deletes every folder f stands for.
+func removeFolder(f TargetFolder) error {
+	var errs []error
+	for _, path := range f.Paths() {
+		if _, err := pkg.RemoveDirectory(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// acquire takes a slot in sem, or returns false if ctx is canceled first.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// firstErr returns the first non-nil error.
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,907 @@
+package pumu
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"pumu/internal/cache"
+)
+
+func TestIsIgnoredPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{".Trash", true},
+		{".vscode", true},
+		{".cache", false}, // only ~/.cache is ignored
+		{"Library", false},
+		{".git", false},
+		{"my-project", false},
+		{"node_modules", false},
+	}
+
+	s := New(Options{})
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result := s.isIgnoredPath(tt.path)
+			if result != tt.expected {
+				t.Errorf("isIgnoredPath(%s) = %v, want %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScanAnchoredIgnoreRules(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("PUMU_TEST_DATA", filepath.Join(root, "data"))
+	mkdirs(t, root, "home/Library/app/node_modules", "home/code/Library/node_modules",
+		"home/code/web/.idea/node_modules", "data/app/node_modules")
+
+	s := New(Options{
+		IgnoredDirs:  []string{".idea", "~/Library", "%PUMU_TEST_DATA%", "$PUMU_TEST_UNSET/x"},
+		ExplainSkips: true,
+		NoCache:      true,
+	})
+	folders, report, err := s.Scan(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	// A project directory called Library is walked; only ~/Library is not.
+	if len(folders) != 1 || folders[0].Path != filepath.Join(home, "code", "Library", "node_modules") {
+		t.Errorf("Scan() folders = %v, want only code/Library/node_modules", folders)
+	}
+	expected := []SkippedDir{
+		{Path: filepath.Join(root, "data"), Rule: "%PUMU_TEST_DATA%"},
+		{Path: filepath.Join(home, "Library"), Rule: "~/Library"},
+		{Path: filepath.Join(home, "code", "web", ".idea"), Rule: ".idea"},
+	}
+	if !reflect.DeepEqual(report.SkippedDirs, expected) {
+		t.Errorf("SkippedDirs = %v, want %v", report.SkippedDirs, expected)
+	}
+}
+
+func TestIsDeletableTarget(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"node_modules", true},
+		{"target", true},
+		{".next", true},
+		{".svelte-kit", true},
+		{".venv", true},
+		{"dist", true},
+		{"build", true},
+		{".gradle", true},
+		{".kotlin", true},
+		{"__pycache__", true},
+		{".mypy_cache", true},
+		{"mypkg.egg-info", true},
+		// bin is a name-level target, anchored to a .NET project file.
+		{"bin", true},
+		{".nuxt", true},
+		{".parcel-cache", true},
+		{"storybook-static", true},
+		{"src", false},
+		{"lib", false},
+		{".config", false},
+		{"Cargo.toml", false},
+		// CACHEDIR.TAG switches tagged caches on; it is no folder name.
+		{"CACHEDIR.TAG", false},
+	}
+
+	s := New(Options{})
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result := s.isDeletableTarget(tt.path)
+			if result != tt.expected {
+				t.Errorf("isDeletableTarget(%s) = %v, want %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+// mkdirs creates each slash-separated dir under root.
+func mkdirs(t *testing.T, root string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o750); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+}
+
+// writeFile creates a file with the given content under root.
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+// findTargetFolders walks root as Scan does and returns the deletable
+// targets it finds, split into those whose parent directory backs them with
+// evidence and those that are unverified. Both slices are sorted.
+func (s *Scanner) findTargetFolders(root string) (targets, unverified []string, err error) {
+	var mu sync.Mutex
+	mounts := newMountPolicy([]string{root}, s.opts.OneFileSystem, s.opts.IncludeMounts)
+
+	err = s.walkTargets(context.Background(), root, mounts, nil, func(path, _ string, verified bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if verified {
+			targets = append(targets, path)
+		} else {
+			unverified = append(unverified, path)
+		}
+	})
+
+	sort.Strings(targets)
+	sort.Strings(unverified)
+	return targets, unverified, err
+}
+
+// isIgnoredPath reports whether a bare-name ignore rule keeps walks out of
+// directories called name.
+func (s *Scanner) isIgnoredPath(name string) bool {
+	_, ok := s.ignored.matchName(name)
+	return ok
+}
+
+// relPaths returns the slash-separated paths of folders relative to root.
+func relPaths(t *testing.T, root string, folders []TargetFolder) []string {
+	t.Helper()
+	paths := []string{}
+	for _, f := range folders {
+		rel, err := filepath.Rel(root, f.Path)
+		if err != nil {
+			t.Fatalf("filepath.Rel(%s, %s) error = %v", root, f.Path, err)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+func TestFindTargetFoldersHonorsPumuignore(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "web/node_modules", "web/dist", "tools/build", "api/node_modules")
+	writeFile(t, root, "web/package.json", "{}")
+	writeFile(t, root, "tools/package.json", "{}")
+	writeFile(t, root, ".pumuignore", "tools/build\n")
+	writeFile(t, root, "web/.pumuignore", "dist/\n")
+
+	targets, _, err := New(Options{}).findTargetFolders(root)
+	if err != nil {
+		t.Fatalf("findTargetFolders() error = %v", err)
+	}
+
+	sort.Strings(targets)
+	expected := []string{
+		filepath.Join(root, "api", "node_modules"),
+		filepath.Join(root, "web", "node_modules"),
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("findTargetFolders() = %v, want %v", targets, expected)
+	}
+}
+
+func TestFindTargetFoldersRequiresEvidence(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root,
+		"rust/target", "web/dist", "web/build", "deploy/build",
+		"java/target", "assets/dist", "app/node_modules",
+	)
+	writeFile(t, root, "rust/Cargo.toml", "")
+	writeFile(t, root, "web/package.json", "{}")
+
+	targets, unverified, err := New(Options{}).findTargetFolders(root)
+	if err != nil {
+		t.Fatalf("findTargetFolders() error = %v", err)
+	}
+
+	sort.Strings(targets)
+	sort.Strings(unverified)
+	expectedTargets := []string{
+		filepath.Join(root, "app", "node_modules"),
+		filepath.Join(root, "rust", "target"),
+		filepath.Join(root, "web", "build"),
+		filepath.Join(root, "web", "dist"),
+	}
+	expectedUnverified := []string{
+		filepath.Join(root, "assets", "dist"),
+		filepath.Join(root, "deploy", "build"),
+		filepath.Join(root, "java", "target"),
+	}
+	if !reflect.DeepEqual(targets, expectedTargets) {
+		t.Errorf("targets = %v, want %v", targets, expectedTargets)
+	}
+	if !reflect.DeepEqual(unverified, expectedUnverified) {
+		t.Errorf("unverified = %v, want %v", unverified, expectedUnverified)
+	}
+}
+
+func TestClassifyTarget(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "rust/target", "py/build", "web/.next", "custom/out", "assets/dist",
+		"maven/target", "kotlin/.gradle", "kotlin/.kotlin", "home/.gradle")
+	writeFile(t, root, "rust/Cargo.toml", "")
+	writeFile(t, root, "py/pyproject.toml", "")
+	writeFile(t, root, "maven/pom.xml", "")
+	writeFile(t, root, "kotlin/settings.gradle.kts", "")
+	mkdirs(t, root, "flutter/build", "flutter/.dart_tool", "hs/.stack-work")
+	writeFile(t, root, "flutter/pubspec.yaml", "")
+
+	tests := []struct {
+		path      string
+		ecosystem string
+		verified  bool
+	}{
+		{"rust/target", "rust", true},
+		{"py/build", "python", true},
+		{"web/.next", "node", true},
+		{"custom/out", "", true},
+		{"assets/dist", "", false},
+		{"maven/target", "java", true},
+		{"kotlin/.gradle", "java", true},
+		{"kotlin/.kotlin", "java", true},
+		{"home/.gradle", "", false},
+		{"flutter/build", "dart", true},
+		{"flutter/.dart_tool", "dart", true},
+		{"hs/.stack-work", "haskell", true},
+	}
+
+	for _, tt := range tests {
+		ecosystem, verified := classifyTarget(filepath.Join(root, tt.path))
+		if ecosystem != tt.ecosystem || verified != tt.verified {
+			t.Errorf("classifyTarget(%q) = %q, %v, want %q, %v", tt.path, ecosystem, verified, tt.ecosystem, tt.verified)
+		}
+	}
+}
+
+func TestFindTargetFoldersAnchorsCommonNames(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "laravel/vendor", "rails/vendor/bundle", "rails/vendor/assets",
+		"gomod/vendor", "misc/bundle", "loose/vendor/bundle",
+		"Api/bin", "Api/obj", "scripts/bin", "phoenix/_build", "phoenix/deps", "docs/_build")
+	writeFile(t, root, "laravel/composer.json", "{}")
+	writeFile(t, root, "rails/Gemfile", "")
+	writeFile(t, root, "gomod/go.mod", "module x")
+	writeFile(t, root, "Api/Api.csproj", "<Project />")
+	writeFile(t, root, "phoenix/mix.exs", "")
+
+	targets, unverified, err := New(Options{}).findTargetFolders(root)
+	if err != nil {
+		t.Fatalf("findTargetFolders() error = %v", err)
+	}
+
+	// Unanchored vendor, bundle, bin and _build directories are neither
+	// targets nor unverified ones, and the walk goes on inside them.
+	expected := []string{
+		filepath.Join(root, "Api", "bin"),
+		filepath.Join(root, "Api", "obj"),
+		filepath.Join(root, "laravel", "vendor"),
+		filepath.Join(root, "phoenix", "_build"),
+		filepath.Join(root, "phoenix", "deps"),
+		filepath.Join(root, "rails", "vendor", "bundle"),
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("targets = %v, want %v", targets, expected)
+	}
+	if len(unverified) != 0 {
+		t.Errorf("unverified = %v, want none", unverified)
+	}
+}
+
+func TestFindTargetFoldersFrameworkCaches(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "web/.nuxt", "web/.turbo", "web/.nx/cache", "web/.angular/cache",
+		"web/coverage", "py/coverage", "misc/cache")
+	writeFile(t, root, "web/package.json", "{}")
+
+	s := New(Options{Targets: []string{".nuxt", ".turbo", "cache", "coverage"}})
+	targets, unverified, err := s.findTargetFolders(root)
+	if err != nil {
+		t.Fatalf("findTargetFolders() error = %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(root, "web", ".angular", "cache"),
+		filepath.Join(root, "web", ".nuxt"),
+		filepath.Join(root, "web", ".nx", "cache"),
+		filepath.Join(root, "web", ".turbo"),
+		filepath.Join(root, "web", "coverage"),
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("targets = %v, want %v", targets, expected)
+	}
+	if want := []string{filepath.Join(root, "py", "coverage")}; !reflect.DeepEqual(unverified, want) {
+		t.Errorf("unverified = %v, want %v", unverified, want)
+	}
+}
+
+func TestScanMeasuresNodeModulesCacheWithNodeModules(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "web/node_modules/.cache/babel-loader", "misc/.cache")
+	writeFile(t, root, "web/package.json", "{}")
+	writeFile(t, root, "web/node_modules/.package-lock.json", "{}")
+	writeFile(t, root, "web/node_modules/.cache/babel-loader/out.json", string(make([]byte, 64*1024)))
+
+	folders, _, err := New(Options{NoCache: true}).Scan(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	// .cache is no target of its own: it goes with its node_modules.
+	if got, want := relPaths(t, root, folders), []string{"web/node_modules"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Scan() = %v, want %v", got, want)
+	}
+	if folders[0].Apparent < 64*1024 {
+		t.Errorf("node_modules Apparent = %d, want at least the %d bytes of its .cache", folders[0].Apparent, 64*1024)
+	}
+
+	// Without node_modules as a target, as with [targets] remove =
+	// ["node_modules"], its .cache is reported and scored on its own.
+	var targets []string
+	for _, name := range DefaultTargets() {
+		if name != "node_modules" {
+			targets = append(targets, name)
+		}
+	}
+	results, _, err := New(Options{Targets: targets, NoCache: true}).Analyze(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Path != filepath.Join(root, "web", "node_modules", ".cache") {
+		t.Fatalf("Analyze() = %+v, want only web/node_modules/.cache", results)
+	}
+	if r := results[0]; r.Ecosystem != "node" || r.Score != 90 {
+		t.Errorf("result = %s %d, want node 90", r.Ecosystem, r.Score)
+	}
+}
+
+func TestFindTargetFoldersGameEngines(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "unity/Library", "unity/Temp", "unity/Logs", "unity/ProjectSettings",
+		"unreal/Intermediate", "unreal/Saved", "unreal/Source", "godot/.godot",
+		"home/Library/Caches/node_modules", "home/Logs")
+	writeFile(t, root, "unity/ProjectSettings/ProjectVersion.txt", "m_EditorVersion: 2022.3.1f1")
+	writeFile(t, root, "unreal/Shooter.uproject", "{}")
+	writeFile(t, root, "godot/project.godot", "")
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	t.Setenv("USERPROFILE", filepath.Join(root, "home"))
+
+	targets, unverified, err := New(Options{}).findTargetFolders(root)
+	if err != nil {
+		t.Fatalf("findTargetFolders() error = %v", err)
+	}
+
+	// ~/Library does not hide a Unity project's Library; home/Library stays
+	// ignored, so nothing inside it is found.
+	expected := []string{
+		filepath.Join(root, "godot", ".godot"),
+		filepath.Join(root, "unity", "Library"),
+		filepath.Join(root, "unity", "Logs"),
+		filepath.Join(root, "unity", "Temp"),
+		filepath.Join(root, "unreal", "Intermediate"),
+		filepath.Join(root, "unreal", "Saved"),
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("targets = %v, want %v", targets, expected)
+	}
+	if len(unverified) != 0 {
+		t.Errorf("unverified = %v, want none", unverified)
+	}
+}
+
+func TestBareIgnoreRuleHidesVerifiedTargets(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "web/build", "web/node_modules")
+	writeFile(t, root, "web/package.json", "{}")
+
+	targets, _, err := New(Options{IgnoredDirs: []string{"build"}}).findTargetFolders(root)
+	if err != nil {
+		t.Fatalf("findTargetFolders() error = %v", err)
+	}
+	if expected := []string{filepath.Join(root, "web", "node_modules")}; !reflect.DeepEqual(targets, expected) {
+		t.Errorf("targets = %v, want %v", targets, expected)
+	}
+}
+
+func TestProjectsOfPicksManagerByEcosystem(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "app/vendor", "app/node_modules", "rails/vendor/bundle")
+	writeFile(t, root, "app/composer.lock", "{}")
+	writeFile(t, root, "app/package-lock.json", "{}")
+	writeFile(t, root, "rails/Gemfile.lock", "")
+
+	app, rails := filepath.Join(root, "app"), filepath.Join(root, "rails")
+	folders := []TargetFolder{
+		{Path: filepath.Join(app, "node_modules"), Ecosystem: "node"},
+		{Path: filepath.Join(app, "vendor"), Ecosystem: "php"},
+		{Path: filepath.Join(rails, "vendor", "bundle"), Ecosystem: "ruby"},
+	}
+
+	expected := []Project{
+		{Dir: app, PM: Npm},
+		{Dir: app, PM: Composer},
+		{Dir: rails, PM: Bundler},
+	}
+	if got := ProjectsOf(folders); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ProjectsOf() = %v, want %v", got, expected)
+	}
+}
+
+func TestAnalyzeUsesManagerOfFolderEcosystem(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "app/vendor", "app/node_modules")
+	writeFile(t, root, "app/composer.lock", "{}")
+	writeFile(t, root, "app/package-lock.json", "{}")
+	old := time.Now().Add(-100 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "app", "composer.lock"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	results, _, err := New(Options{NoCache: true}).Analyze(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	scores := make(map[string]int)
+	for _, r := range results {
+		scores[filepath.Base(r.Path)] = r.Score
+	}
+	// vendor is judged by the stale composer.lock, node_modules by the fresh
+	// package-lock.json.
+	if scores["vendor"] != 80 || scores["node_modules"] != 20 {
+		t.Errorf("scores = %v, want vendor 80 and node_modules 20", scores)
+	}
+}
+
+func TestAnalyzeScoresNotebookCheckpointsAsCache(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "notebooks/.ipynb_checkpoints")
+	writeFile(t, root, "notebooks/analysis.ipynb", "{}")
+
+	results, _, err := New(Options{NoCache: true}).Analyze(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Analyze() = %v, want only .ipynb_checkpoints", results)
+	}
+	// No lockfile backs checkpoints, but they are a cache, not an orphan.
+	if r := results[0]; r.Score != 90 || r.Reason != "🟢 Build cache (re-generable)" {
+		t.Errorf("result = %d %q, want 90 %q", r.Score, r.Reason, "🟢 Build cache (re-generable)")
+	}
+}
+
+func TestScanFindsPythonVenvsAndCaches(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "api/env/lib", "api/venv-old/lib", "api/.pytest_cache", "api/src/mypkg.egg-info", "notes/env")
+	writeFile(t, root, "api/pyproject.toml", "")
+	writeFile(t, root, "api/env/pyvenv.cfg", "home = /usr/bin")
+	writeFile(t, root, "api/venv-old/pyvenv.cfg", "home = /usr/bin")
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"defaults", Options{}, []string{"api/.pytest_cache", "api/env", "api/src/mypkg.egg-info", "api/venv-old"}},
+		{"venvs count as .venv", Options{Filter: Filter{Types: []string{".venv"}}}, []string{"api/env", "api/venv-old"}},
+		{"pattern type", Options{Filter: Filter{Types: []string{"*.egg-info"}}}, []string{"api/src/mypkg.egg-info"}},
+		{"without .venv", Options{Targets: []string{".pytest_cache"}}, []string{"api/.pytest_cache"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.NoCache = true
+			folders, _, err := New(tt.opts).Scan(context.Background(), []string{root})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			got := relPaths(t, root, folders)
+			for _, f := range folders {
+				if f.Ecosystem != "python" {
+					t.Errorf("%s: Ecosystem = %q, want python", f.Path, f.Ecosystem)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanAggregatesPycache(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "api/__pycache__", "api/app/__pycache__", "api/app/models/__pycache__", "scripts/__pycache__")
+	writeFile(t, root, "api/requirements.txt", "")
+	writeFile(t, root, "api/app/__pycache__/a.pyc", "12345")
+	writeFile(t, root, "api/app/models/__pycache__/b.pyc", "123")
+	writeFile(t, root, "scripts/__pycache__/c.pyc", "1")
+
+	s := New(Options{NoCache: true})
+	folders, _, err := s.Scan(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(folders) != 2 {
+		t.Fatalf("Scan() = %v, want one folder per project", folders)
+	}
+
+	// The api project's three __pycache__ folders are one aggregate.
+	api := folders[0]
+	if want := filepath.Join(root, "api", "**", "__pycache__"); api.Path != want {
+		t.Errorf("aggregate Path = %q, want %q", api.Path, want)
+	}
+	if len(api.Members) != 3 || api.Apparent != 8 {
+		t.Errorf("aggregate = %d members, %d bytes, want 3 members, 8 bytes", len(api.Members), api.Apparent)
+	}
+	// A lone __pycache__ outside any project is reported as itself.
+	if want := filepath.Join(root, "scripts", "__pycache__"); folders[1].Path != want || folders[1].Members != nil {
+		t.Errorf("folders[1] = %+v, want plain %s", folders[1], want)
+	}
+
+	if _, err := s.Delete(context.Background(), folders[:1]); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	for _, path := range api.Members {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after Delete()", path)
+		}
+	}
+}
+
+func TestScanSizesEveryDiscoveredTarget(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "a/node_modules/x", "b/node_modules", "c/dist")
+	writeFile(t, root, "a/node_modules/x/index.js", "12345")
+	writeFile(t, root, "b/node_modules/index.js", "123")
+
+	var events []Event
+	s := New(Options{
+		OneFileSystem: true,
+		NoCache:       true,
+		OnEvent:       func(e Event) { events = append(events, e) },
+	})
+	folders, report, err := s.Scan(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	expected := []TargetFolder{
+		{Path: filepath.Join(root, "a", "node_modules"), Apparent: 5},
+		{Path: filepath.Join(root, "b", "node_modules"), Apparent: 3},
+	}
+	if len(folders) != len(expected) {
+		t.Fatalf("Scan() found %d folders, want %d", len(folders), len(expected))
+	}
+	for i, f := range folders {
+		if f.Path != expected[i].Path || f.Apparent != expected[i].Apparent {
+			t.Errorf("folders[%d] = %+v, want path %s, apparent %d", i, f, expected[i].Path, expected[i].Apparent)
+		}
+	}
+	// Each folder is reported found before it is reported sized.
+	seen := make(map[string]EventKind)
+	for _, e := range events {
+		switch {
+		case e.Kind == TargetFound && seen[e.Folder.Path] == "":
+		case e.Kind == FolderSized && seen[e.Folder.Path] == TargetFound:
+		default:
+			t.Errorf("unexpected %s event for %s after %q", e.Kind, e.Folder.Path, seen[e.Folder.Path])
+		}
+		seen[e.Folder.Path] = e.Kind
+	}
+	for _, f := range folders {
+		if seen[f.Path] != FolderSized {
+			t.Errorf("last event for %s = %q, want %s", f.Path, seen[f.Path], FolderSized)
+		}
+	}
+	if want := []string{filepath.Join(root, "c", "dist")}; !reflect.DeepEqual(report.Unverified, want) {
+		t.Errorf("report.Unverified = %v, want %v", report.Unverified, want)
+	}
+}
+
+func TestScanCustomTargets(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "a/node_modules", "a/out", "vendor/b/out")
+
+	s := New(Options{Targets: []string{"out"}, IgnoredDirs: []string{"vendor"}, NoCache: true})
+	folders, _, err := s.Scan(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(folders) != 1 || folders[0].Path != filepath.Join(root, "a", "out") {
+		t.Errorf("Scan() = %+v, want only a/out", folders)
+	}
+}
+
+func TestDirSizeDeduplicatesHardlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hardlink accounting needs inode numbers")
+	}
+
+	root := t.TempDir()
+	mkdirs(t, root, "a", "b", "store")
+	writeFile(t, root, "a/shared.js", "shared content")
+	writeFile(t, root, "a/own.js", "only in a")
+	writeFile(t, root, "store/pkg.js", "lives in the store")
+	link := func(oldname, newname string) {
+		if err := os.Link(filepath.Join(root, oldname), filepath.Join(root, newname)); err != nil {
+			t.Skipf("hardlinks not supported: %v", err)
+		}
+	}
+	link("a/shared.js", "a/shared-again.js") // twice inside a
+	link("a/shared.js", "b/shared.js")       // and once inside b
+	link("store/pkg.js", "b/pkg.js")         // b links into a store nobody deletes
+
+	links := newLinkTracker()
+	mounts := newMountPolicy([]string{root}, true, false)
+	a, err := dirSize(filepath.Join(root, "a"), links, mounts)
+	if err != nil {
+		t.Fatalf("dirSize(a) error = %v", err)
+	}
+	b, err := dirSize(filepath.Join(root, "b"), links, mounts)
+	if err != nil {
+		t.Fatalf("dirSize(b) error = %v", err)
+	}
+
+	alloc := func(name string) int64 {
+		t.Helper()
+		info, err := os.Lstat(filepath.Join(root, name))
+		if err != nil {
+			t.Fatalf("lstat %s: %v", name, err)
+		}
+		return statFile(info).allocated
+	}
+
+	if want := int64(2*len("shared content") + len("only in a")); a.Apparent != want {
+		t.Errorf("a.Apparent = %d, want %d", a.Apparent, want)
+	}
+	// The shared file's last link is in b, so b gets credited with it; the
+	// store-linked file is never reclaimable.
+	if want := alloc("a") + alloc("a/own.js"); a.Reclaimable != want {
+		t.Errorf("a.Reclaimable = %d, want %d", a.Reclaimable, want)
+	}
+	if want := alloc("b") + alloc("a/shared.js"); b.Reclaimable != want {
+		t.Errorf("b.Reclaimable = %d, want %d", b.Reclaimable, want)
+	}
+	if !a.Shared || !b.Shared {
+		t.Errorf("Shared = %v, %v, want both true", a.Shared, b.Shared)
+	}
+}
+
+func TestMeasureFolderUsesCache(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "app/node_modules/pkg")
+	writeFile(t, root, "app/package-lock.json", "{}")
+	writeFile(t, root, "app/node_modules/pkg/index.js", "module.exports = 1")
+	folder := filepath.Join(root, "app", "node_modules")
+
+	index := cache.Open(filepath.Join(t.TempDir(), cache.FileName))
+	mounts := newMountPolicy([]string{root}, true, false)
+
+	measured := measureFolder(folder, newLinkTracker(), mounts, index)
+	if measured.Files != 1 {
+		t.Fatalf("Files = %d, want 1", measured.Files)
+	}
+
+	// Plant a different size under the same signature: an unchanged folder
+	// must be served from the index instead of being walked again.
+	sig, err := cache.Signature(folder, lockfilePaths(filepath.Join(root, "app"))...)
+	if err != nil {
+		t.Fatalf("Signature() error = %v", err)
+	}
+	index.Store(folder, cache.Entry{Apparent: 42, Reclaimable: 42, Signature: sig})
+	if got := measureFolder(folder, newLinkTracker(), mounts, index); got.Reclaimable != 42 {
+		t.Errorf("unchanged folder: Reclaimable = %d, want cached 42", got.Reclaimable)
+	}
+
+	// Touching the lockfile invalidates the entry.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "app", "package-lock.json"), later, later); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	if got := measureFolder(folder, newLinkTracker(), mounts, index); got != measured {
+		t.Errorf("after lockfile change: got %+v, want %+v", got, measured)
+	}
+}
+
+func TestMountPolicy(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("relies on /proc being a separate pseudo filesystem")
+	}
+	procInfo, err := os.Stat("/proc")
+	if err != nil {
+		t.Skip("no /proc")
+	}
+	rootInfo, err := os.Stat("/")
+	if err != nil || statFile(rootInfo).id.dev == statFile(procInfo).id.dev {
+		t.Skip("/proc is not a separate mount")
+	}
+
+	tests := []struct {
+		name          string
+		oneFileSystem bool
+		includeMounts bool
+		expected      bool
+	}{
+		{"one filesystem", true, false, false},
+		{"crossing, pseudo fs skipped", false, false, false},
+		{"crossing, mounts included", false, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newMountPolicy([]string{"/"}, tt.oneFileSystem, tt.includeMounts)
+			if got := p.allows("/proc", procInfo); got != tt.expected {
+				t.Errorf("allows(/proc) = %v, want %v", got, tt.expected)
+			}
+			if !p.allows("/", rootInfo) {
+				t.Error("allows(/) = false, want true for the root filesystem")
+			}
+		})
+	}
+}
+
+func TestNormalizeRoots(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "work/app", "oss")
+	work := filepath.Join(root, "work")
+	app := filepath.Join(work, "app")
+	oss := filepath.Join(root, "oss")
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(work, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		roots    []string
+		expected []string
+	}{
+		{"disjoint roots kept in order", []string{oss, work}, []string{oss, work}},
+		{"nested root dropped", []string{app, work}, []string{work}},
+		{"duplicate keeps first spelling", []string{work, work + "/"}, []string{work}},
+		{"symlinked duplicate dropped", []string{link, work}, []string{link}},
+		{"missing root kept", []string{filepath.Join(root, "missing"), oss}, []string{filepath.Join(root, "missing"), oss}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeRoots(tt.roots); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("NormalizeRoots(%v) = %v, want %v", tt.roots, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDeleteStopsWhenCanceled(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "a/node_modules", "b/node_modules")
+	folders := []TargetFolder{
+		{Path: filepath.Join(root, "a", "node_modules"), Size: 1},
+		{Path: filepath.Join(root, "b", "node_modules"), Size: 2},
+	}
+	s := New(Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := s.Delete(ctx, folders)
+	if !errors.Is(err, context.Canceled) || len(res.Removed) != 0 || res.Freed != 0 {
+		t.Errorf("Delete() after cancel = %+v, %v, want nothing removed and context.Canceled", res, err)
+	}
+	for _, f := range folders {
+		if _, err := os.Stat(f.Path); err != nil {
+			t.Errorf("%s was deleted after cancel", f.Path)
+		}
+	}
+
+	res, err = s.Delete(context.Background(), folders)
+	if err != nil || len(res.Removed) != 2 || res.Freed != 3 {
+		t.Errorf("Delete() = %+v, %v, want 2 removed and 3 bytes freed", res, err)
+	}
+}
+
+func TestScanSkipsVenvsThatAreProjects(t *testing.T) {
+	root := t.TempDir()
+	// python -m venv . turns the project itself into a venv.
+	mkdirs(t, root, "app/.git", "app/src", "app/lib", "tool/lib")
+	writeFile(t, root, "app/pyvenv.cfg", "home = /usr/bin")
+	writeFile(t, root, "app/requirements.txt", "")
+	writeFile(t, root, "tool/pyvenv.cfg", "home = /usr/bin")
+
+	targets, _, err := New(Options{}).findTargetFolders(root)
+	if err != nil {
+		t.Fatalf("findTargetFolders() error = %v", err)
+	}
+	if expected := []string{filepath.Join(root, "tool")}; !reflect.DeepEqual(targets, expected) {
+		t.Errorf("targets = %v, want %v", targets, expected)
+	}
+
+	// Nor is the walk root itself a target.
+	targets, _, err = New(Options{}).findTargetFolders(filepath.Join(root, "tool"))
+	if err != nil {
+		t.Fatalf("findTargetFolders() error = %v", err)
+	}
+	if len(targets) != 0 {
+		t.Errorf("targets of a venv root = %v, want none", targets)
+	}
+}
+
+func TestScanFindsTargetsBySignature(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "rust/target", "tools/out/data", "tools/notes", "web/modules/pkg", "lib/src")
+	tag := "Signature: 8a477f597d28d172789f06886806bc55\n# This file is a cache directory tag.\n"
+	// target has no Cargo.toml next to it, as with CARGO_TARGET_DIR, but is tagged.
+	writeFile(t, root, "rust/target/CACHEDIR.TAG", tag)
+	writeFile(t, root, "tools/out/CACHEDIR.TAG", tag)
+	writeFile(t, root, "tools/notes/CACHEDIR.TAG", "Signature: not a cache\n")
+	// pnpm's modules-dir setting renames node_modules.
+	writeFile(t, root, "web/modules/.modules.yaml", "layoutVersion: 5")
+	// Stray markers in a project, or in the root, do not make it a target.
+	writeFile(t, root, "lib/Cargo.toml", "")
+	writeFile(t, root, "lib/CACHEDIR.TAG", tag)
+	writeFile(t, root, "lib/.package-lock.json", "{}")
+	writeFile(t, root, "CACHEDIR.TAG", tag)
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"defaults", Options{}, []string{"rust/target", "tools/out", "web/modules"}},
+		{"tagged type", Options{Filter: Filter{Types: []string{"CACHEDIR.TAG"}}}, []string{"tools/out"}},
+		{"node_modules type", Options{Filter: Filter{Types: []string{"node_modules"}}}, []string{"web/modules"}},
+		{"without CACHEDIR.TAG", Options{Targets: []string{"target", "node_modules"}}, []string{"rust/target", "web/modules"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.NoCache = true
+			folders, report, err := New(tt.opts).Scan(context.Background(), []string{root})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			got := relPaths(t, root, folders)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+			if len(report.Unverified) != 0 {
+				t.Errorf("Unverified = %v, want none", report.Unverified)
+			}
+		})
+	}
+}
+
+func TestScanNeverTargetsCacheTagDirs(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "a/CACHEDIR.TAG", "b/CACHEDIR.TAG/node_modules")
+
+	for _, targets := range [][]string{nil, {"CACHEDIR.TAG", "CACHEDIR.*", "node_modules"}} {
+		s := New(Options{Targets: targets, IncludeUnverified: true, NoCache: true})
+		folders, _, err := s.Scan(context.Background(), []string{root})
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		want := []string{"b/CACHEDIR.TAG/node_modules"}
+		if got := relPaths(t, root, folders); !reflect.DeepEqual(got, want) {
+			t.Errorf("Scan() with targets %v = %v, want %v", targets, got, want)
+		}
+	}
+}
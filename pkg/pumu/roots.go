@@ -0,0 +1,66 @@
+package pumu
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// NormalizeRoots drops roots that repeat or lie inside another root, so that
+// overlapping roots are only walked once. The first spelling of each root is
+// kept, in the order given.
+func NormalizeRoots(roots []string) []string {
+	abs := make([]string, len(roots))
+	for i, root := range roots {
+		abs[i] = canonicalPath(root)
+	}
+
+	var kept []string
+	for i, root := range roots {
+		if !coveredByOther(abs, i) {
+			kept = append(kept, root)
+		}
+	}
+	return kept
+}
+
+// coveredByOther reports whether abs[i] repeats an earlier root or lies
+// strictly inside any other root.
+func coveredByOther(abs []string, i int) bool {
+	for j := range abs {
+		if j == i {
+			continue
+		}
+		if abs[j] == abs[i] {
+			if j < i {
+				return true
+			}
+			continue
+		}
+		if isWithin(abs[j], abs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalPath returns the absolute, symlink-free form of path, falling back
+// to a cleaned path when it cannot be resolved.
+func canonicalPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// isWithin reports whether path lies strictly inside dir.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
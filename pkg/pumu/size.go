@@ -0,0 +1,194 @@
+package pumu
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"pumu/internal/cache"
+	"pumu/internal/pkg"
+)
+
+// diskUsage is what a folder takes up, measured two ways.
+type diskUsage struct {
+	Apparent    int64 // sum of file sizes, like `du --apparent-size`
+	Reclaimable int64 // allocated bytes that deleting the folder would free
+	Files       int64
+	// Shared is set when some file in the folder has links outside it, which
+	// makes Reclaimable depend on which other folders were measured.
+	Shared bool
+}
+
+// fileID identifies a file across hardlinks.
+type fileID struct {
+	dev, ino uint64
+}
+
+// fileStat is the platform-specific part of a file's metadata.
+type fileStat struct {
+	id        fileID
+	nlink     uint64 // 0 when the platform cannot report links
+	allocated int64
+}
+
+// linkTracker counts the links of multiply-linked files seen during a run, so
+// that a file is only reclaimable once every one of its links has been found
+// inside the folders being measured. It is shared by all size workers.
+type linkTracker struct {
+	mu   sync.Mutex
+	seen map[fileID]uint64
+}
+
+func newLinkTracker() *linkTracker {
+	return &linkTracker{seen: make(map[fileID]uint64)}
+}
+
+// lastLink records one more link to id and reports whether it completes the
+// file's nlink, i.e. whether the file is freed once all seen links are deleted.
+func (t *linkTracker) lastLink(id fileID, nlink uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seen[id]++
+	return t.seen[id] == nlink
+}
+
+// dirSize measures the folder at path. Files with several hardlinks are
+// counted as reclaimable only once, in the folder where their last link is
+// found; files with links outside the measured folders (e.g. pnpm's
+// node_modules, linked into the global store) are not reclaimable at all.
+// Mount points that mounts rejects are not measured.
+func dirSize(path string, links *linkTracker, mounts *mountPolicy) (diskUsage, error) {
+	var usage diskUsage
+	missing := make(map[fileID]uint64) // links of multiply-linked files not yet seen in this folder
+	err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && p != path && !mounts.allows(p, info) {
+			return filepath.SkipDir
+		}
+		usage.add(info, d.IsDir(), links, missing)
+		return nil
+	})
+
+	for _, n := range missing {
+		if n > 0 {
+			usage.Shared = true
+			break
+		}
+	}
+	return usage, err
+}
+
+func (u *diskUsage) add(info fs.FileInfo, isDir bool, links *linkTracker, missing map[fileID]uint64) {
+	st := statFile(info)
+
+	if isDir {
+		// Directory entries take up blocks too, but have no apparent size.
+		u.Reclaimable += st.allocated
+		return
+	}
+
+	u.Files++
+	u.Apparent += info.Size()
+	if st.nlink <= 1 {
+		u.Reclaimable += st.allocated
+		return
+	}
+
+	if n, ok := missing[st.id]; ok {
+		missing[st.id] = n - 1
+	} else {
+		missing[st.id] = st.nlink - 1
+	}
+	if links.lastLink(st.id, st.nlink) {
+		u.Reclaimable += st.allocated
+	}
+}
+
+// openSizeIndex opens the on-disk size index, or returns nil when caching is
+// disabled or there is nowhere to keep the cache.
+func (s *Scanner) openSizeIndex() *cache.Index {
+	if s.opts.NoCache {
+		return nil
+	}
+	path, err := cache.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	return cache.Open(path)
+}
+
+// measureTarget returns the size of f, adding up its members if it is an aggregate.
+func measureTarget(f TargetFolder, links *linkTracker, mounts *mountPolicy, index *cache.Index) diskUsage {
+	var total diskUsage
+	for _, path := range f.Paths() {
+		usage := measureFolder(path, links, mounts, index)
+		total.Apparent += usage.Apparent
+		total.Reclaimable += usage.Reclaimable
+		total.Files += usage.Files
+		total.Shared = total.Shared || usage.Shared
+	}
+	return total
+}
+
+// measureFolder returns the size of the folder at path, reusing the entry in
+// index if the folder's signature has not changed since it was stored.
+// Folders sharing hardlinks with other folders are always measured, since
+// what deleting them frees depends on what else is measured in this run.
+func measureFolder(path string, links *linkTracker, mounts *mountPolicy, index *cache.Index) diskUsage {
+	if index == nil {
+		usage, _ := dirSize(path, links, mounts)
+		return usage
+	}
+
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = path
+	}
+	sig, sigErr := cache.Signature(key, lockfilePaths(projectDirOf(key))...)
+	if sigErr == nil {
+		if e, ok := index.Lookup(key, sig); ok {
+			return diskUsage{Apparent: e.Apparent, Reclaimable: e.Reclaimable, Files: e.Files}
+		}
+	}
+
+	usage, err := dirSize(path, links, mounts)
+	if err == nil && sigErr == nil && !usage.Shared {
+		index.Store(key, cache.Entry{
+			Apparent:    usage.Apparent,
+			Reclaimable: usage.Reclaimable,
+			Files:       usage.Files,
+			Signature:   sig,
+		})
+	}
+	return usage
+}
+
+// lockfileManagers lists, in a fixed order, the package managers whose
+// lockfiles are part of a target folder's cache signature.
+var lockfileManagers = []pkg.PackageManager{
+	pkg.Npm, pkg.Pnpm, pkg.Yarn, pkg.Bun, pkg.Deno, pkg.Cargo, pkg.Go, pkg.Pip,
+	pkg.Maven, pkg.Gradle, pkg.Composer, pkg.Bundler,
+	pkg.Uv, pkg.Poetry, pkg.Pipenv, pkg.Pdm, pkg.Conda,
+	pkg.Dotnet, pkg.Mix, pkg.Pub, pkg.Stack, pkg.Cabal,
+}
+
+// lockfilePaths returns every lockfile path a project in dir could have.
+// All managers are listed, not just the detected one, so the signature does
+// not depend on detection when a project has several lockfiles.
+func lockfilePaths(dir string) []string {
+	var paths []string
+	for _, pm := range lockfileManagers {
+		for _, name := range pkg.Lockfiles(pm) {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	return paths
+}
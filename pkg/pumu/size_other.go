@@ -0,0 +1,14 @@
+//go:build !unix
+
+package pumu
+
+import "io/fs"
+
+// statFile falls back to the apparent size where block counts and inodes
+// are unavailable, so no hardlink deduplication takes place.
+func statFile(info fs.FileInfo) fileStat {
+	if info.IsDir() {
+		return fileStat{}
+	}
+	return fileStat{allocated: info.Size()}
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package pumu
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// statFile reads the inode, link count and allocated blocks of info.
+func statFile(info fs.FileInfo) fileStat {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileStat{allocated: info.Size()}
+	}
+	return fileStat{
+		id:        fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, //nolint:unconvert // field types differ across platforms
+		nlink:     uint64(st.Nlink),                                 //nolint:unconvert // field types differ across platforms
+		allocated: int64(st.Blocks) * 512,                           //nolint:unconvert // st_blocks is always in 512-byte units
+	}
+}
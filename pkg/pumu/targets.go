@@ -0,0 +1,205 @@
+package pumu
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"pumu/internal/pkg"
+)
+
+// defaultIgnoredDirs contains directories that pumu should never descend into.
+// Tool and OS directories are anchored to where they live, so that a project
+// directory called Library or Local elsewhere is still walked.
+var defaultIgnoredDirs = []string{
+	".Trash", ".vscode", ".idea",
+	"~/.cache", "~/.npm", "~/.yarn", "~/.cargo", "~/.rustup",
+	"~/Library", "~/AppData", "%APPDATA%", "%LOCALAPPDATA%",
+}
+
+// defaultTargets contains known heavy dependency/build folders: the names
+// and patterns of the target table, and CACHEDIR.TAG, which stands for any
+// directory tagged as a cache.
+var defaultTargets = append(pkg.TargetNames(), pkg.CacheTag)
+
+// DefaultTargets returns the folder names and patterns deleted by default, sorted.
+func DefaultTargets() []string { return sortedCopy(defaultTargets) }
+
+// DefaultIgnoredDirs returns the directory names skipped by default, sorted.
+func DefaultIgnoredDirs() []string { return sortedCopy(defaultIgnoredDirs) }
+
+func sortedCopy(names []string) []string {
+	out := append([]string(nil), names...)
+	sort.Strings(out)
+	return out
+}
+
+// matchPattern returns the first of patterns that name matches.
+func matchPattern(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// signedTarget reports whether the directory dir, with entries, is a target
+// by its contents, whatever it is called: a folder of the table with one of
+// its signature files, such as a virtual environment's pyvenv.cfg, or a
+// cache with a valid CACHEDIR.TAG. Only targets the scanner deletes count.
+// Neither makes a target of root, the directory being walked, or of a
+// project directory: a venv created in place with `python -m venv .`, or a
+// stray tag or state file, does not make the project's source tree deletable.
+func (s *Scanner) signedTarget(root, dir string, entries []fs.DirEntry) (ecosystem string, ok bool) {
+	if dir == root {
+		return "", false
+	}
+	// Most directories have neither, so look for them before checking
+	// whether dir is a project.
+	signed, tagged := false, false
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if t, ok := s.signatures[entry.Name()]; ok && !signed {
+			ecosystem, signed = t.Ecosystem, true
+		}
+		if entry.Name() == pkg.CacheTag && s.cacheTags {
+			tagged = true
+		}
+	}
+	if !signed && !tagged {
+		return "", false
+	}
+	if isProjectDir(entries) {
+		return "", false
+	}
+	if signed {
+		return ecosystem, true
+	}
+	return "", pkg.HasCacheTag(dir)
+}
+
+// isProjectDir reports whether a directory with entries is a project, with
+// a .git or a package manager's or target's manifest.
+func isProjectDir(entries []fs.DirEntry) bool {
+	for _, entry := range entries {
+		if entry.Name() == ".git" || (!entry.IsDir() && pkg.IsManifest(entry.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the type of the target at path that --type and
+// Filter.Types refer to: the pattern it matches, such as *.egg-info, the
+// table name whose signature it has, such as ".venv" for any virtual
+// environment, CACHEDIR.TAG for any other tagged cache, or else its name.
+func typeOf(path string) string {
+	name := filepath.Base(path)
+	if entries := pkg.TargetsNamed(name); len(entries) > 0 {
+		return entries[0].Name
+	}
+	for _, t := range pkg.Targets {
+		if t.Signed(path) {
+			return t.Name
+		}
+	}
+	if pkg.HasCacheTag(path) {
+		return pkg.CacheTag
+	}
+	return name
+}
+
+// matchTarget returns the entry of the target table that the target at path
+// matches, if any, and whether its name has any entry at all. Failing its
+// manifests, an entry also matches a folder with one of its signature files
+// or a CACHEDIR.TAG, which back it up just as well.
+func matchTarget(path string) (entry pkg.Target, matched, known bool) {
+	entries := pkg.TargetsNamed(filepath.Base(path))
+	for _, t := range entries {
+		if t.Matches(path) {
+			return t, true, true
+		}
+	}
+	for _, t := range entries {
+		if t.Signed(path) || pkg.HasCacheTag(path) {
+			return t, true, true
+		}
+	}
+	return pkg.Target{}, false, len(entries) > 0
+}
+
+// projectDirOf returns the directory of the project the target at path
+// belongs to: its parent, the directory above it for targets that live
+// under a fixed directory, such as vendor/bundle, or the project of an
+// aggregate such as <project>/**/__pycache__.
+func projectDirOf(path string) string {
+	parent := filepath.Dir(path)
+	if filepath.Base(parent) == aggregateDir {
+		return filepath.Dir(parent)
+	}
+	if isNestedTarget(path) {
+		return filepath.Dir(parent)
+	}
+	return parent
+}
+
+// isNestedTarget reports whether the target at path sits under the fixed
+// directory one of its name's entries requires, such as vendor/bundle.
+func isNestedTarget(path string) bool {
+	parent := filepath.Base(filepath.Dir(path))
+	for _, t := range pkg.TargetsNamed(filepath.Base(path)) {
+		if t.Under != "" && t.Under == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// isAnchored reports whether the target at path is a target at all: its
+// name is not anchored, or one of its entries matches it.
+func isAnchored(path string) bool {
+	entries := pkg.TargetsNamed(filepath.Base(path))
+	anchored := false
+	for _, t := range entries {
+		if t.Matches(path) {
+			return true
+		}
+		anchored = anchored || t.Anchored
+	}
+	return !anchored
+}
+
+// isAggregated reports whether folders called name are reported as one
+// aggregate per project.
+func isAggregated(name string) bool {
+	entries := pkg.TargetsNamed(name)
+	return len(entries) > 0 && entries[0].Aggregated
+}
+
+// isDeletableTarget reports whether folders called name are targets.
+// CACHEDIR.TAG names the tag file of a cache, never a folder, even when a
+// target pattern matches it.
+func (s *Scanner) isDeletableTarget(name string) bool {
+	if name == pkg.CacheTag {
+		return false
+	}
+	if s.targets[name] {
+		return true
+	}
+	_, ok := matchPattern(s.targetPatterns, name)
+	return ok
+}
+
+// classifyTarget reports the ecosystem of the target at path, "" if unknown,
+// and whether it matches an entry of the target table. Targets the table does
+// not know need no evidence.
+func classifyTarget(path string) (ecosystem string, verified bool) {
+	t, matched, known := matchTarget(path)
+	if !known {
+		return "", true
+	}
+	return t.Ecosystem, matched
+}
@@ -0,0 +1,129 @@
+package pumu
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// walkDirs walks the directory tree rooted at root using up to workers
+// concurrent directory reads. visit is called, possibly from several
+// goroutines at once, for root and for every directory below it; returning
+// false stops the walk from descending into that directory. Unless inspect
+// is nil, it is then called with the entries of each directory entered, once
+// they are read; returning false stops the walk from visiting its
+// subdirectories, so a directory can be judged by its contents without
+// reading them twice. Like filepath.WalkDir, symlinks below root are not
+// followed, and unreadable directories are silently skipped. Visit order is
+// not deterministic, so callers must sort whatever they collect.
+func walkDirs(root string, workers int, visit func(path string, d fs.DirEntry) bool, inspect func(dir string, entries []fs.DirEntry) bool) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !visit(root, fs.FileInfoToDirEntry(info)) || !info.IsDir() {
+		return nil
+	}
+
+	q := newDirQueue()
+	q.push(root)
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				dir, ok := q.pop()
+				if !ok {
+					return
+				}
+				entries := readDir(dir)
+				if inspect != nil && !inspect(dir, entries) {
+					q.done()
+					continue
+				}
+				for _, entry := range entries {
+					if !entry.IsDir() {
+						continue
+					}
+					path := filepath.Join(dir, entry.Name())
+					if visit(path, entry) {
+						q.push(path)
+					}
+				}
+				q.done()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// readDir returns the entries of dir in directory order, skipping the sort
+// os.ReadDir does, or nil if dir cannot be read.
+func readDir(dir string) []fs.DirEntry {
+	f, err := os.Open(dir) //nolint:gosec // walking user-chosen directories is the point
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	entries, _ := f.ReadDir(-1)
+	return entries
+}
+
+// dirQueue is a LIFO work queue of directories that knows when the walk is
+// over: when it is empty and no worker is still reading a directory that
+// could push more.
+type dirQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	paths   []string
+	pending int // queued plus in-progress directories
+}
+
+func newDirQueue() *dirQueue {
+	q := &dirQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *dirQueue) push(path string) {
+	q.mu.Lock()
+	q.paths = append(q.paths, path)
+	q.pending++
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// pop blocks until a directory is available, or returns false once the walk is done.
+func (q *dirQueue) pop() (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.paths) == 0 && q.pending > 0 {
+		q.cond.Wait()
+	}
+	if len(q.paths) == 0 {
+		return "", false
+	}
+
+	path := q.paths[len(q.paths)-1]
+	q.paths = q.paths[:len(q.paths)-1]
+	return path, true
+}
+
+// done marks a popped directory as fully read.
+func (q *dirQueue) done() {
+	q.mu.Lock()
+	q.pending--
+	finished := q.pending == 0
+	q.mu.Unlock()
+
+	if finished {
+		q.cond.Broadcast()
+	}
+}
@@ -0,0 +1,115 @@
+package pumu
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// buildTree creates a synthetic tree under root with the given fan-out and
+// depth. Every leaf project gets a package.json and a node_modules folder.
+func buildTree(tb testing.TB, root string, fanout, depth int) {
+	tb.Helper()
+	var build func(dir string, level int)
+	build = func(dir string, level int) {
+		if level == depth {
+			nm := filepath.Join(dir, "node_modules", "pkg")
+			if err := os.MkdirAll(nm, 0o750); err != nil {
+				tb.Fatalf("failed to create %s: %v", nm, err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0o600); err != nil {
+				tb.Fatalf("failed to write package.json: %v", err)
+			}
+			return
+		}
+		for i := 0; i < fanout; i++ {
+			build(filepath.Join(dir, fmt.Sprintf("d%d", i)), level+1)
+		}
+	}
+	build(root, 0)
+}
+
+func TestWalkDirsMatchesWalkDir(t *testing.T) {
+	root := t.TempDir()
+	buildTree(t, root, 3, 3)
+	mkdirs(t, root, "d0/.git/objects", "d1/skip/inner")
+
+	prune := func(name string) bool { return name == ".git" || name == "skip" || name == "node_modules" }
+
+	var expected []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		expected = append(expected, path)
+		if prune(d.Name()) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDir() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var got []string
+	err = walkDirs(root, 8, func(path string, d fs.DirEntry) bool {
+		mu.Lock()
+		got = append(got, path)
+		mu.Unlock()
+		return !prune(d.Name())
+	}, nil)
+	if err != nil {
+		t.Fatalf("walkDirs() error = %v", err)
+	}
+
+	sort.Strings(expected)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("walkDirs() visited %d dirs, WalkDir visited %d", len(got), len(expected))
+	}
+}
+
+func TestWalkDirsMissingRoot(t *testing.T) {
+	err := walkDirs(filepath.Join(t.TempDir(), "missing"), 4, func(string, fs.DirEntry) bool { return true }, nil)
+	if err == nil {
+		t.Error("walkDirs() expected error for missing root")
+	}
+}
+
+// keepWalking is the pruning rule shared by both benchmark walkers.
+func keepWalking(s *Scanner, d fs.DirEntry) bool {
+	name := d.Name()
+	return name != ".git" && !s.isIgnoredPath(name) && !s.isDeletableTarget(name)
+}
+
+func BenchmarkWalk(b *testing.B) {
+	root := b.TempDir()
+	buildTree(b, root, 6, 4) // 1296 projects, ~3k directories
+	s := New(Options{})
+
+	b.Run("WalkDir", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+				if err == nil && d.IsDir() && !keepWalking(s, d) {
+					return filepath.SkipDir
+				}
+				return nil
+			})
+		}
+	})
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("walkDirs-%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = walkDirs(root, workers, func(_ string, d fs.DirEntry) bool {
+					return keepWalking(s, d)
+				}, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package pumu
+
+import (
+	"path/filepath"
+	"sync"
+
+	"pumu/internal/pkg"
+)
+
+// workspaceFinder finds the workspaces that directories found in a scan
+// belong to, looking no higher than the root each directory was found
+// under, so that a workspace declared above the scan is never picked up.
+// What each directory declares is read once and memoized, since every
+// project and folder of a monorepo looks up through the same directories.
+// It is safe for concurrent use; a nil *workspaceFinder looks up every
+// directory afresh with pkg.FindWorkspace.
+type workspaceFinder struct {
+	roots map[string]bool
+
+	mu       sync.Mutex
+	declared map[string]*pkg.Workspace // directory -> its workspace, nil if it declares none
+}
+
+func newWorkspaceFinder(roots []string) *workspaceFinder {
+	w := &workspaceFinder{
+		roots:    make(map[string]bool, len(roots)),
+		declared: make(map[string]*pkg.Workspace),
+	}
+	for _, root := range roots {
+		w.roots[filepath.Clean(root)] = true
+	}
+	return w
+}
+
+// find returns the workspace dir belongs to, like pkg.FindWorkspace: the
+// nearest workspace declared in dir or above it, up to its root, if dir is
+// its root or one of its members.
+func (w *workspaceFinder) find(dir string) (pkg.Workspace, bool) {
+	if w == nil {
+		return pkg.FindWorkspace(dir)
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		if ws := w.read(d); ws != nil {
+			if !ws.Includes(dir) {
+				return pkg.Workspace{}, false
+			}
+			return *ws, true
+		}
+		if w.roots[d] || d == filepath.Dir(d) {
+			return pkg.Workspace{}, false
+		}
+	}
+}
+
+// read returns the workspace declared in dir, or nil if there is none. The
+// lock is only held around the memo, not while reading, so workers racing
+// up the same directories may both read them, to the same result.
+func (w *workspaceFinder) read(dir string) *pkg.Workspace {
+	w.mu.Lock()
+	ws, ok := w.declared[dir]
+	w.mu.Unlock()
+	if ok {
+		return ws
+	}
+
+	if found, ok := pkg.ReadWorkspace(dir); ok {
+		ws = &found
+	}
+	w.mu.Lock()
+	w.declared[dir] = ws
+	w.mu.Unlock()
+	return ws
+}
+
+// projectOf returns the project whose dependencies dir's are installed by,
+// like ProjectOf.
+func (w *workspaceFinder) projectOf(dir string) (Project, bool) {
+	if w == nil {
+		return ProjectOf(dir)
+	}
+	if ws, ok := w.find(dir); ok {
+		return Project{Dir: ws.Root, PM: ws.PM, Workspace: true}, true
+	}
+	// find has read dir: it declares no workspace.
+	pm := pkg.DetectManager(dir)
+	return Project{Dir: dir, PM: pm}, pm != pkg.Unknown
+}
+
+// isMember reports whether dir is a member of a workspace rooted above it.
+func (w *workspaceFinder) isMember(dir string) bool {
+	ws, ok := w.find(dir)
+	return ok && ws.Root != dir
+}